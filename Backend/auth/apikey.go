@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"prophecy/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader заголовок запроса с API ключом сервисного аккаунта
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix начало каждого API ключа, чтобы ключ было легко узнать в логах и конфигурации
+const apiKeyPrefix = "prk_"
+
+// GenerateAPIKey генерирует новый API ключ вида prk_<префикс>_<секрет>.
+// Возвращает сам ключ, открытый префикс для поиска и хеш для хранения в базе данных.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	prefix, err = randomToken(6)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = apiKeyPrefix + prefix + "_" + secret
+	return key, prefix, hashAPIKey(key), nil
+}
+
+// hashAPIKey возвращает хеш API ключа для хранения в базе данных
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticateAPIKey находит действующий ключ по его значению.
+// Возвращает nil, если ключ не найден, отозван, истек или не совпадает хеш.
+func authenticateAPIKey(key string) (*models.APIKey, error) {
+	rest, ok := strings.CutPrefix(key, apiKeyPrefix)
+	if !ok {
+		return nil, nil
+	}
+
+	prefix, _, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" {
+		return nil, nil
+	}
+
+	apiKey, err := models.GetActiveAPIKeyByPrefix(prefix)
+	if err != nil || apiKey == nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(apiKey.KeyHash)) != 1 {
+		return nil, nil
+	}
+
+	if apiKey.IsExpired() {
+		return nil, nil
+	}
+
+	return apiKey, nil
+}
+
+// JWTOrAPIKeyMiddleware middleware, принимающий JWT токен пользователя или API ключ сервисного аккаунта.
+// Без заголовка X-API-Key запрос проверяется как в JWTAuthMiddleware. Для ключа требуется область readScope
+// на запросы GET и HEAD и область writeScope на остальные запросы.
+func JWTOrAPIKeyMiddleware(readScope, writeScope string) gin.HandlerFunc {
+	jwtMiddleware := JWTAuthMiddleware()
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			jwtMiddleware(c)
+			return
+		}
+
+		apiKey, err := authenticateAPIKey(key)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
+			c.Abort()
+			return
+		}
+
+		if apiKey == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.Abort()
+			return
+		}
+
+		// Определение нужной области доступа по методу запроса
+		scope := writeScope
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = readScope
+		}
+
+		if !apiKey.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key does not have the required scope", "scope": scope})
+			c.Abort()
+			return
+		}
+
+		user, err := models.GetTelegramUserByID(apiKey.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
+			c.Abort()
+			return
+		}
+
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.Abort()
+			return
+		}
+
+		// Заблокированный пользователь сервисного аккаунта не может пользоваться ключами
+		permissions, err := currentPermissions(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
+			c.Abort()
+			return
+		}
+
+		if permissions != nil {
+			if suspension := activeSuspension(permissions); suspension != nil {
+				c.JSON(http.StatusForbidden, SuspendedResponse(suspension))
+				c.Abort()
+				return
+			}
+		}
+
+		// Время последнего использования не влияет на ответ, поэтому ошибка только логируется
+		if err := models.TouchAPIKey(apiKey.ID); err != nil {
+			log.Printf("Failed to update API key %d last use: %v", apiKey.ID, err)
+		}
+
+		// Сохранение пользователя сервисного аккаунта в контексте.
+		// Сервисные аккаунты никогда не получают права администратора.
+		c.Set("user_id", user.ID)
+		c.Set("telegram_id", user.TelegramID)
+		c.Set("tenant_id", user.TenantID)
+		c.Set("generated_name", user.GeneratedName)
+		c.Set("is_admin", false)
+		c.Set("role", user.Role)
+		c.Set("service_account_id", apiKey.ServiceAccountID)
+		c.Set("api_key_id", apiKey.ID)
+		c.Set("scopes", apiKey.Scopes)
+
+		c.Next()
+	}
+}
+
+// RequireAPIKeyScope middleware, требующий область scope для запросов с API ключом независимо от метода.
+// Используется после JWTOrAPIKeyMiddleware для GET маршрутов, которые изменяют данные.
+// Запросы с JWT токеном пропускаются без проверки.
+func RequireAPIKeyScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, isAPIKey := c.Get("scopes")
+		if !isAPIKey {
+			c.Next()
+			return
+		}
+
+		if !slices.Contains(scopes.([]string), scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key does not have the required scope", "scope": scope})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
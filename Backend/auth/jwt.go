@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"log"
 	"time"
 
 	"prophecy/backend/config"
@@ -13,58 +14,65 @@ import (
 type JWTClaims struct {
 	UserID        int    `json:"user_id"`
 	TelegramID    int64  `json:"telegram_id"`
+	TenantID      string `json:"tenant,omitempty"` // бот, через которого вошел пользователь
 	GeneratedName string `json:"generated_name"`
 	IsAdmin       bool   `json:"is_admin"`
 	Role          string `json:"role"`
+	SessionID     string `json:"sid,omitempty"` // ID входа, к которому относится токен
+	PermVersion   int    `json:"pv"`            // версия прав пользователя на момент выдачи токена
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT генерирует JWT токен для пользователя
-func GenerateJWT(user *models.TelegramUser) (string, error) {
+// GenerateJWT генерирует короткоживущий JWT токен доступа для входа пользователя
+func GenerateJWT(user *models.TelegramUser, loginID string) (string, error) {
 	cfg := config.GetConfig()
 
 	// Создание claims с пользовательскими данными
 	claims := &JWTClaims{
 		UserID:        user.ID,
 		TelegramID:    user.TelegramID,
+		TenantID:      user.TenantID,
 		GeneratedName: user.GeneratedName,
 		IsAdmin:       user.IsAdmin,
 		Role:          user.Role,
+		SessionID:     loginID,
+		PermVersion:   user.PermVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Токен действует 24 часа
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "prophecy-backend",
 		},
 	}
 
-	// Создание токена
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Подпись токена
-	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
+	ring, err := getKeyring()
 	if err != nil {
 		return "", err
 	}
 
-	return tokenString, nil
+	// Подпись токена текущим ключом из набора ключей
+	return ring.sign(claims)
 }
 
 // ValidateJWT проверяет JWT токен и возвращает claims
 func ValidateJWT(tokenString string) (*JWTClaims, error) {
-	cfg := config.GetConfig()
-
-	// Парсинг токена
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
+	ring, err := getKeyring()
+	if err != nil {
+		return nil, err
+	}
 
+	// Парсинг токена, ключ проверки выбирается по kid из заголовка
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, ring.verificationKey, jwt.WithValidMethods(ring.validMethods()))
 	if err != nil {
 		return nil, err
 	}
 
 	// Проверка валидности токена
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		// Каждый принятый старый токен логируется, чтобы было видно, когда JWT_ACCEPT_LEGACY_HS256 можно отключить
+		if kid, _ := token.Header["kid"].(string); kid == "" && ring.isLegacy() {
+			log.Printf("Accepted legacy HS256 token for user %d (expires %v)", claims.UserID, claims.ExpiresAt)
+		}
 		return claims, nil
 	}
 
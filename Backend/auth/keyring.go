@@ -0,0 +1,298 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"prophecy/backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey ключ подписи или проверки JWT токенов
+type signingKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer    // nil для ключей, оставленных только для проверки
+	Public  crypto.PublicKey // *rsa.PublicKey или ed25519.PublicKey
+}
+
+// Keyring набор ключей JWT. Токены подписываются одним ключом, а проверяются любым ключом
+// из набора, поэтому при смене ключа ранее выданные токены продолжают действовать.
+// Если асимметричные ключи не настроены, используется HS256 с JWT_SECRET.
+type Keyring struct {
+	signing   *signingKey
+	keys      map[string]*signingKey
+	legacyKey []byte // секрет HS256 для токенов без kid, nil если такие токены не принимаются
+	// Момент, после которого токены HS256 без kid больше не принимаются при асимметричных ключах (нулевой - без срока)
+	legacyUntil time.Time
+}
+
+var (
+	keyringOnce sync.Once
+	keyring     *Keyring
+	keyringErr  error
+)
+
+// LoadKeyring загружает ключи JWT из JWT_KEYS_DIR. Вызывается при запуске, чтобы ошибки
+// в ключах обнаруживались сразу, а не при первом запросе.
+func LoadKeyring() error {
+	_, err := getKeyring()
+	return err
+}
+
+// getKeyring возвращает загруженный набор ключей
+func getKeyring() (*Keyring, error) {
+	keyringOnce.Do(func() {
+		keyring, keyringErr = loadKeyring(config.GetConfig())
+	})
+	return keyring, keyringErr
+}
+
+// knownJWTSecrets общеизвестные значения JWT_SECRET из прежних настроек по умолчанию и docker-compose.yml.
+// Токены, подписанные ими, может выпустить кто угодно, поэтому такие секреты не принимаются.
+var knownJWTSecrets = []string{
+	"prophecy_jwt_secret_key",
+	"prophecy_jwt_secret_key_change_in_production",
+}
+
+// checkJWTSecret проверяет, что секрет HS256 задан и не является общеизвестным
+func checkJWTSecret(secret string) error {
+	if secret == "" {
+		return errors.New("JWT_SECRET is not set: set a random secret or configure asymmetric keys in JWT_KEYS_DIR")
+	}
+	if slices.Contains(knownJWTSecrets, secret) {
+		return errors.New("JWT_SECRET is set to a publicly known default value: set a random secret or configure asymmetric keys in JWT_KEYS_DIR")
+	}
+	return nil
+}
+
+// loadKeyring читает PEM файлы ключей из каталога. Имя файла без расширения используется как kid.
+// Закрытые ключи (RSA или Ed25519) используются для подписи и проверки, открытые - только для проверки.
+func loadKeyring(cfg *config.Config) (*Keyring, error) {
+	ring := &Keyring{keys: map[string]*signingKey{}}
+
+	if cfg.JWTKeysDir != "" {
+		paths, err := filepath.Glob(filepath.Join(cfg.JWTKeysDir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			id := strings.TrimSuffix(filepath.Base(path), ".pem")
+			key, err := loadSigningKey(id, path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load JWT key %s: %w", path, err)
+			}
+			ring.keys[id] = key
+		}
+	}
+
+	// Без асимметричных ключей токены подписываются HS256, как раньше, но только секретом,
+	// который не задан по умолчанию
+	if len(ring.keys) == 0 {
+		if err := checkJWTSecret(cfg.JWTSecret); err != nil {
+			return nil, err
+		}
+		ring.legacyKey = []byte(cfg.JWTSecret)
+		return ring, nil
+	}
+
+	signingID := cfg.JWTSigningKeyID
+	if signingID == "" {
+		// По умолчанию подписываем последним по имени закрытым ключом,
+		// поэтому ключи удобно называть по дате создания
+		var ids []string
+		for id, key := range ring.keys {
+			if key.Private != nil {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		if len(ids) > 0 {
+			signingID = ids[len(ids)-1]
+		}
+	}
+
+	signing, ok := ring.keys[signingID]
+	if !ok || signing.Private == nil {
+		return nil, fmt.Errorf("JWT signing key %q not found among private keys in %s", signingID, cfg.JWTKeysDir)
+	}
+	ring.signing = signing
+
+	// Токены, подписанные HS256 до перехода на асимметричные ключи, принимаются только явно:
+	// до истечения их срока и, если задан JWT_LEGACY_HS256_UNTIL, не дольше указанного момента
+	if cfg.JWTAcceptLegacy {
+		if err := checkJWTSecret(cfg.JWTSecret); err != nil {
+			return nil, fmt.Errorf("JWT_ACCEPT_LEGACY_HS256 is enabled: %w", err)
+		}
+		ring.legacyKey = []byte(cfg.JWTSecret)
+
+		if cfg.JWTLegacyUntil != "" {
+			until, err := time.Parse(time.RFC3339, cfg.JWTLegacyUntil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWT_LEGACY_HS256_UNTIL: %w", err)
+			}
+			ring.legacyUntil = until
+		}
+	}
+
+	return ring, nil
+}
+
+// loadSigningKey загружает ключ из PEM файла
+func loadSigningKey(id, path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, k, k.Public()
+	case *rsa.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, k
+	case ed25519.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+
+	return key, nil
+}
+
+// sign подписывает токен текущим ключом подписи
+func (r *Keyring) sign(claims jwt.Claims) (string, error) {
+	if r.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(r.legacyKey)
+	}
+
+	token := jwt.NewWithClaims(r.signing.Method, claims)
+	token.Header["kid"] = r.signing.ID
+	return token.SignedString(r.signing.Private)
+}
+
+// verificationKey возвращает ключ для проверки токена по его заголовкам kid и alg
+func (r *Keyring) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if r.legacyKey == nil || token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("token has no key ID")
+		}
+		if r.isLegacy() && !r.legacyUntil.IsZero() && time.Now().After(r.legacyUntil) {
+			return nil, fmt.Errorf("legacy HS256 tokens are no longer accepted")
+		}
+		return r.legacyKey, nil
+	}
+
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+
+	// Алгоритм токена должен соответствовать типу ключа
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+
+	return key.Public, nil
+}
+
+// isLegacy проверяет, что токены HS256 без kid принимаются только для совместимости
+// со старыми токенами, а новые подписываются асимметричным ключом
+func (r *Keyring) isLegacy() bool {
+	return r.signing != nil && r.legacyKey != nil
+}
+
+// validMethods возвращает алгоритмы, которые принимаются при проверке токенов
+func (r *Keyring) validMethods() []string {
+	methods := []string{}
+	if r.legacyKey != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodRS256, jwt.SigningMethodEdDSA} {
+		for _, key := range r.keys {
+			if key.Method == method {
+				methods = append(methods, method.Alg())
+				break
+			}
+		}
+	}
+	return methods
+}
+
+// JWK открытый ключ в формате JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS возвращает открытые ключи проверки токенов, отсортированные по kid
+func JWKS() ([]JWK, error) {
+	ring, err := getKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := []JWK{}
+	for _, key := range ring.keys {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks = append(jwks, jwk)
+	}
+
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks, nil
+}
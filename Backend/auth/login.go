@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+)
+
+// TokenPair пара токенов, выдаваемая при входе и обновлении
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // время жизни токена доступа в секундах
+}
+
+// randomToken генерирует случайную строку из n байт в шестнадцатеричном виде
+func randomToken(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// hashRefreshToken возвращает хеш refresh токена для хранения в базе данных
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAccessToken выдает токен доступа и описывает его вместе с refresh токеном
+func newAccessToken(user *models.TelegramUser, loginID, refreshToken string) (*TokenPair, error) {
+	accessToken, err := GenerateJWT(user, loginID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(config.GetConfig().AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// IssueLogin создает новый вход пользователя и выдает для него пару токенов
+func IssueLogin(user *models.TelegramUser, userAgent, ipAddress string) (*TokenPair, error) {
+	loginID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	login := &models.Login{
+		ID:        loginID,
+		UserID:    user.ID,
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+		ExpiresAt: time.Now().Add(config.GetConfig().RefreshTokenTTL),
+	}
+
+	if err := models.CreateLogin(login, hashRefreshToken(refreshToken)); err != nil {
+		return nil, err
+	}
+
+	return newAccessToken(user, loginID, refreshToken)
+}
+
+// RefreshLogin обменивает refresh токен на новую пару токенов.
+// Старый refresh токен становится недействительным, а его повторное использование отзывает вход.
+// Токен доступа выдается с актуальными данными пользователя из базы данных.
+func RefreshLogin(refreshToken string) (*TokenPair, *models.TelegramUser, error) {
+	newRefreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	expiresAt := time.Now().Add(config.GetConfig().RefreshTokenTTL)
+	login, err := models.RotateRefreshToken(hashRefreshToken(refreshToken), hashRefreshToken(newRefreshToken), expiresAt)
+	if err == models.ErrRefreshTokenReused {
+		// Вход отозван, токены доступа этого входа перестают действовать сразу
+		forgetLogin(login.ID)
+		return nil, nil, err
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := models.GetTelegramUserByID(login.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, models.ErrRefreshTokenInvalid
+	}
+
+	// Заблокированный пользователь не получает новых токенов. Пользователь возвращается вместе
+	// с ошибкой, чтобы ответ мог содержать причину и срок блокировки.
+	suspension, err := models.GetActiveUserSuspension(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if suspension != nil {
+		return nil, user, models.ErrUserSuspended
+	}
+
+	pair, err := newAccessToken(user, login.ID, newRefreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, user, nil
+}
@@ -4,9 +4,14 @@ import (
 	"net/http"
 	"strings"
 
+	"prophecy/backend/config"
+
 	"github.com/gin-gonic/gin"
 )
 
+// RefreshedTokenHeader заголовок ответа с перевыпущенным токеном, если права пользователя изменились
+const RefreshedTokenHeader = "X-Refreshed-Token"
+
 // JWTAuthMiddleware middleware для проверки JWT токена
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -36,37 +41,71 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Сохранение claims в контексте
-		c.Set("user_id", claims.UserID)
-		c.Set("telegram_id", claims.TelegramID)
-		c.Set("generated_name", claims.GeneratedName)
-		c.Set("is_admin", claims.IsAdmin)
-		c.Set("role", claims.Role)
-		c.Set("claims", claims)
+		// Проверка, что вход, к которому относится токен, не отозван.
+		// Токены, выданные до появления входов, не содержат sid и действуют до истечения срока.
+		if claims.SessionID != "" {
+			active, err := isLoginActive(claims.SessionID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
+				c.Abort()
+				return
+			}
 
-		// Продолжение выполнения
-		c.Next()
-	}
-}
+			if !active {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "message": "login has been revoked"})
+				c.Abort()
+				return
+			}
+		}
 
-// AdminAuthMiddleware middleware для проверки прав администратора
-func AdminAuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Получение информации о правах администратора из контекста
-		isAdmin, exists := c.Get("is_admin")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin privileges required"})
+		// Права в токене могли устареть: если версия прав пользователя изменилась,
+		// токен перевыпускается с актуальными правами и возвращается в заголовке X-Refreshed-Token
+		permissions, err := currentPermissions(claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
 			c.Abort()
 			return
 		}
 
-		// Проверка, является ли пользователь администратором
-		if !isAdmin.(bool) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin privileges required"})
+		if permissions == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "message": "user not found"})
 			c.Abort()
 			return
 		}
 
+		// Токены заблокированного пользователя не принимаются до окончания блокировки
+		if suspension := activeSuspension(permissions); suspension != nil {
+			c.JSON(http.StatusForbidden, SuspendedResponse(suspension))
+			c.Abort()
+			return
+		}
+
+		if permissions.PermVersion != claims.PermVersion {
+			refreshed, token, err := refreshClaims(claims, permissions)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+				c.Abort()
+				return
+			}
+			claims = refreshed
+			c.Header(RefreshedTokenHeader, token)
+		}
+
+		// Токены, выданные до появления нескольких ботов, относятся к боту по умолчанию
+		tenantID := claims.TenantID
+		if tenantID == "" {
+			tenantID = config.DefaultTenantID
+		}
+
+		// Сохранение claims в контексте
+		c.Set("user_id", claims.UserID)
+		c.Set("telegram_id", claims.TelegramID)
+		c.Set("tenant_id", tenantID)
+		c.Set("generated_name", claims.GeneratedName)
+		c.Set("is_admin", claims.IsAdmin)
+		c.Set("role", claims.Role)
+		c.Set("claims", claims)
+
 		// Продолжение выполнения
 		c.Next()
 	}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+)
+
+// cachedPermissions закешированные права пользователя
+type cachedPermissions struct {
+	permissions *models.UserPermissions
+	checkedAt   time.Time
+}
+
+// permissionCache кеширует актуальные права пользователей, чтобы не обращаться к базе данных на каждый запрос.
+// Изменение прав на другом экземпляре бэкенда начинает действовать не позже чем через PermissionCacheTTL.
+var permissionCache = struct {
+	sync.Mutex
+	entries map[int]cachedPermissions
+}{entries: map[int]cachedPermissions{}}
+
+// currentPermissions возвращает актуальные права пользователя или nil, если пользователь не найден
+func currentPermissions(userID int) (*models.UserPermissions, error) {
+	ttl := config.GetConfig().PermissionCacheTTL
+	now := time.Now()
+
+	permissionCache.Lock()
+	entry, ok := permissionCache.entries[userID]
+	permissionCache.Unlock()
+
+	if ok && now.Sub(entry.checkedAt) < ttl {
+		return entry.permissions, nil
+	}
+
+	permissions, err := models.GetUserPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionCache.Lock()
+	// Удаляем устаревшие записи, чтобы кеш не рос бесконечно
+	if len(permissionCache.entries) > 10000 {
+		for id, cached := range permissionCache.entries {
+			if now.Sub(cached.checkedAt) >= ttl {
+				delete(permissionCache.entries, id)
+			}
+		}
+	}
+	permissionCache.entries[userID] = cachedPermissions{permissions: permissions, checkedAt: now}
+	permissionCache.Unlock()
+
+	return permissions, nil
+}
+
+// ForgetUserPermissions сбрасывает закешированные права пользователя на этом экземпляре бэкенда.
+// Вызывается после изменения прав, чтобы они начали действовать сразу.
+func ForgetUserPermissions(userID int) {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+
+	delete(permissionCache.entries, userID)
+}
+
+// refreshClaims перевыпускает токен с актуальными правами пользователя.
+// Возвращает обновленные claims и новый токен того же входа.
+func refreshClaims(claims *JWTClaims, permissions *models.UserPermissions) (*JWTClaims, string, error) {
+	user := &models.TelegramUser{
+		ID:            claims.UserID,
+		TelegramID:    claims.TelegramID,
+		TenantID:      claims.TenantID,
+		GeneratedName: claims.GeneratedName,
+		IsAdmin:       permissions.IsAdmin,
+		Role:          permissions.Role,
+		PermVersion:   permissions.PermVersion,
+	}
+
+	token, err := GenerateJWT(user, claims.SessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshed := *claims
+	refreshed.IsAdmin = permissions.IsAdmin
+	refreshed.Role = permissions.Role
+	refreshed.PermVersion = permissions.PermVersion
+	return &refreshed, token, nil
+}
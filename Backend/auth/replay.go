@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+)
+
+// ErrInitDataReplayed возвращается при повторном использовании данных аутентификации Telegram
+var ErrInitDataReplayed = errors.New("init data has already been used")
+
+// replayCache хранит использованные данные аутентификации до истечения их срока действия
+var replayCache = struct {
+	sync.Mutex
+	entries map[string]time.Time
+}{entries: map[string]time.Time{}}
+
+// replayKey возвращает ключ данных аутентификации: query_id, а если его нет - hash
+func replayKey(data *TelegramWebAppData) string {
+	if data.QueryID != "" {
+		return "query_id:" + data.QueryID
+	}
+	return "hash:" + data.Hash
+}
+
+// ClaimInitData отмечает проверенные данные аутентификации использованными.
+// Повторное использование тех же данных до истечения их срока возвращает ErrInitDataReplayed.
+// Кеш в памяти защищает один экземпляр бэкенда, хранилище "postgres" - все экземпляры.
+func ClaimInitData(data *TelegramWebAppData) error {
+	cfg := config.GetConfig()
+
+	authDate, err := strconv.ParseInt(data.AuthDate, 10, 64)
+	if err != nil {
+		return err
+	}
+	// После истечения срока данные отклоняются проверкой возраста, поэтому хранить ключ дольше не нужно
+	expiresAt := time.Unix(authDate, 0).Add(cfg.TelegramAuthMaxAge)
+	key := replayKey(data)
+	now := time.Now()
+
+	replayCache.Lock()
+	if until, ok := replayCache.entries[key]; ok && now.Before(until) {
+		replayCache.Unlock()
+		return ErrInitDataReplayed
+	}
+	// Удаляем истекшие записи, чтобы кеш не рос бесконечно
+	if len(replayCache.entries) > 10000 {
+		for k, until := range replayCache.entries {
+			if !now.Before(until) {
+				delete(replayCache.entries, k)
+			}
+		}
+	}
+	replayCache.entries[key] = expiresAt
+	replayCache.Unlock()
+
+	if cfg.TelegramReplayStore == "postgres" {
+		claimed, err := models.ClaimAuthReplayKey(key, expiresAt)
+		if err != nil {
+			// Данные не использованы: разрешаем повторить попытку после ошибки базы данных
+			replayCache.Lock()
+			delete(replayCache.entries, key)
+			replayCache.Unlock()
+			return err
+		}
+		if !claimed {
+			return ErrInitDataReplayed
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+)
+
+// loginStatus закешированный результат проверки входа
+type loginStatus struct {
+	active    bool
+	checkedAt time.Time
+}
+
+// loginCache кеширует проверку отзыва входов, чтобы не обращаться к базе данных на каждый запрос.
+// Отзыв на другом экземпляре бэкенда начинает действовать не позже чем через LoginCheckCacheTTL.
+var loginCache = struct {
+	sync.Mutex
+	entries map[string]loginStatus
+}{entries: map[string]loginStatus{}}
+
+// isLoginActive проверяет, что вход не отозван и не истек
+func isLoginActive(loginID string) (bool, error) {
+	ttl := config.GetConfig().LoginCheckCacheTTL
+	now := time.Now()
+
+	loginCache.Lock()
+	status, ok := loginCache.entries[loginID]
+	loginCache.Unlock()
+
+	if ok && now.Sub(status.checkedAt) < ttl {
+		return status.active, nil
+	}
+
+	active, err := models.IsLoginActive(loginID)
+	if err != nil {
+		return false, err
+	}
+
+	loginCache.Lock()
+	// Удаляем устаревшие записи, чтобы кеш не рос бесконечно
+	if len(loginCache.entries) > 10000 {
+		for id, entry := range loginCache.entries {
+			if now.Sub(entry.checkedAt) >= ttl {
+				delete(loginCache.entries, id)
+			}
+		}
+	}
+	loginCache.entries[loginID] = loginStatus{active: active, checkedAt: now}
+	loginCache.Unlock()
+
+	return active, nil
+}
+
+// forgetLogin отмечает вход отозванным в кеше этого экземпляра бэкенда
+func forgetLogin(loginIDs ...string) {
+	now := time.Now()
+
+	loginCache.Lock()
+	defer loginCache.Unlock()
+
+	for _, id := range loginIDs {
+		loginCache.entries[id] = loginStatus{active: false, checkedAt: now}
+	}
+}
+
+// RevokeLogin отзывает вход пользователя. Возвращает false, если действующего входа с таким ID нет.
+func RevokeLogin(userID int, loginID, reason string) (bool, error) {
+	revoked, err := models.RevokeLogin(userID, loginID, reason)
+	if err != nil {
+		return false, err
+	}
+
+	forgetLogin(loginID)
+	return revoked, nil
+}
+
+// RevokeUserLogins отзывает все входы пользователя, кроме exceptLoginID. Возвращает количество отозванных входов.
+func RevokeUserLogins(userID int, exceptLoginID, reason string) (int, error) {
+	ids, err := models.RevokeUserLogins(userID, exceptLoginID, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	forgetLogin(ids...)
+	return len(ids), nil
+}
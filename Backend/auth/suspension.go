@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"time"
+
+	"prophecy/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuspendedResponse формирует тело ответа 403 для заблокированного пользователя
+// с причиной и сроком блокировки (suspended_until равен null для бессрочной блокировки)
+func SuspendedResponse(suspension *models.UserSuspension) gin.H {
+	message := "Your account is suspended permanently"
+	if suspension.ExpiresAt != nil {
+		message = "Your account is suspended until " + suspension.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if suspension.Reason != "" {
+		message += ". Reason: " + suspension.Reason
+	}
+
+	return gin.H{
+		"error":           "User is suspended",
+		"code":            "user_suspended",
+		"message":         message,
+		"reason":          suspension.Reason,
+		"suspended_until": suspension.ExpiresAt,
+	}
+}
+
+// activeSuspension возвращает блокировку из прав пользователя, если она еще действует.
+// Права кешируются, поэтому истекшая за время кеширования блокировка проверяется по сроку.
+func activeSuspension(permissions *models.UserPermissions) *models.UserSuspension {
+	if permissions.Suspension == nil || !permissions.Suspension.IsActive(time.Now()) {
+		return nil
+	}
+	return permissions.Suspension
+}
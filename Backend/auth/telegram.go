@@ -2,16 +2,20 @@
 package auth
 
 import (
+	"crypto/ed25519"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"prophecy/backend/config"
 )
 
 // TelegramWebAppData структура для хранения данных из Telegram WebApp
@@ -23,18 +27,38 @@ type TelegramWebAppData struct {
 	PhotoURL  string `json:"photo_url"`
 	AuthDate  string `json:"auth_date"`
 	Hash      string `json:"hash"`
+	QueryID   string `json:"query_id"`
 }
 
 // TelegramAuth структура для проверки аутентификации
 type TelegramAuth struct {
 	Token string
+	// Максимальный возраст данных аутентификации
+	MaxAge time.Duration
+	// ID бота и публичный ключ Telegram для проверки initData по подписи Ed25519
+	BotID     int64
+	PublicKey ed25519.PublicKey
 }
 
 // NewTelegramAuth создает новый экземпляр TelegramAuth
 func NewTelegramAuth(token string) *TelegramAuth {
 	return &TelegramAuth{
-		Token: token,
+		Token:  token,
+		MaxAge: config.GetConfig().TelegramAuthMaxAge,
+	}
+}
+
+// checkAuthDate проверяет, что данные аутентификации не старше MaxAge
+func (ta *TelegramAuth) checkAuthDate(authDateStr string) error {
+	authDateInt, err := strconv.ParseInt(authDateStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid auth_date format: %v", err)
+	}
+
+	if time.Since(time.Unix(authDateInt, 0)) > ta.MaxAge {
+		return fmt.Errorf("auth data is too old")
 	}
+	return nil
 }
 
 // ValidateInitData проверяет initData строку из Telegram WebApp
@@ -51,39 +75,18 @@ func (ta *TelegramAuth) ValidateInitData(initData string) (bool, *TelegramWebApp
 		return false, nil, fmt.Errorf("hash not found in init data")
 	}
 
-	// Проверяем время аутентификации (не старше 1 дня)
+	// Проверяем время аутентификации (не старше MaxAge)
 	authDateStr := parsedData.Get("auth_date")
 	if authDateStr == "" {
 		return false, nil, fmt.Errorf("auth_date not found in init data")
 	}
 
-	authDateInt, err := strconv.ParseInt(authDateStr, 10, 64)
-	if err != nil {
-		return false, nil, fmt.Errorf("invalid auth_date format: %v", err)
+	if err := ta.checkAuthDate(authDateStr); err != nil {
+		return false, nil, err
 	}
 
-	if time.Now().Unix()-authDateInt > 86400 {
-		return false, nil, fmt.Errorf("auth data is too old")
-	}
-
-	// Удаляем хеш из данных и сортируем ключи
-	var keys []string
-	for key := range parsedData {
-		if key != "hash" {
-			keys = append(keys, key)
-		}
-	}
-	sort.Strings(keys)
-
 	// Создаем строку для проверки в формате key=value
-	var items []string
-	for _, key := range keys {
-		values := parsedData[key]
-		if len(values) > 0 {
-			items = append(items, key+"="+values[0])
-		}
-	}
-	dataCheckString := strings.Join(items, "\n")
+	dataCheckString := buildDataCheckString(parsedData)
 
 	// Генерируем секретный ключ
 	secretKey := hmacSHA256([]byte(ta.Token), []byte("WebAppData"))
@@ -105,10 +108,32 @@ func (ta *TelegramAuth) ValidateInitData(initData string) (bool, *TelegramWebApp
 	return true, userData, nil
 }
 
+// buildDataCheckString собирает строку для проверки подписи: все поля, кроме hash и skip,
+// в формате key=value, отсортированные по ключу и разделенные переводом строки
+func buildDataCheckString(data url.Values, skip ...string) string {
+	var keys []string
+	for key := range data {
+		if key != "hash" && !slices.Contains(skip, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var items []string
+	for _, key := range keys {
+		values := data[key]
+		if len(values) > 0 {
+			items = append(items, key+"="+values[0])
+		}
+	}
+	return strings.Join(items, "\n")
+}
+
 // extractUserData извлекает данные пользователя из parsedData
 func extractUserData(parsedData url.Values) (*TelegramWebAppData, error) {
 	userData := &TelegramWebAppData{
-		Hash: parsedData.Get("hash"),
+		Hash:    parsedData.Get("hash"),
+		QueryID: parsedData.Get("query_id"),
 	}
 
 	// Парсим auth_date как string
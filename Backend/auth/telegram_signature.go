@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"prophecy/backend/config"
+)
+
+// ParseTelegramPublicKey разбирает публичный ключ Ed25519 в hex формате
+func ParseTelegramPublicKey(hexKey string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: %d", len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// NewTelegramSignatureAuth создает экземпляр TelegramAuth для проверки initData по подписи
+// Telegram без токена бота
+func NewTelegramSignatureAuth(botID int64, publicKey ed25519.PublicKey) *TelegramAuth {
+	return &TelegramAuth{
+		BotID:     botID,
+		PublicKey: publicKey,
+		MaxAge:    config.GetConfig().TelegramAuthMaxAge,
+	}
+}
+
+// ValidateInitDataSignature проверяет initData по подписи Ed25519 из поля signature.
+// Подписывается строка "<bot_id>:WebAppData\n" и строка проверки из всех полей, кроме hash и signature.
+func (ta *TelegramAuth) ValidateInitDataSignature(initData string) (bool, *TelegramWebAppData, error) {
+	if len(ta.PublicKey) != ed25519.PublicKeySize {
+		return false, nil, fmt.Errorf("public key is not configured")
+	}
+
+	parsedData, err := url.ParseQuery(initData)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to parse init data: %v", err)
+	}
+
+	signatureStr := parsedData.Get("signature")
+	if signatureStr == "" {
+		return false, nil, fmt.Errorf("signature not found in init data")
+	}
+
+	// Подпись передается в base64url без выравнивания
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(signatureStr, "="))
+	if err != nil {
+		return false, nil, fmt.Errorf("invalid signature format: %v", err)
+	}
+
+	// Проверяем время аутентификации (не старше MaxAge)
+	authDateStr := parsedData.Get("auth_date")
+	if authDateStr == "" {
+		return false, nil, fmt.Errorf("auth_date not found in init data")
+	}
+
+	if err := ta.checkAuthDate(authDateStr); err != nil {
+		return false, nil, err
+	}
+
+	message := strconv.FormatInt(ta.BotID, 10) + ":WebAppData\n" + buildDataCheckString(parsedData, "signature")
+	if !ed25519.Verify(ta.PublicKey, []byte(message), signature) {
+		return false, nil, fmt.Errorf("invalid signature")
+	}
+
+	userData, err := extractUserData(parsedData)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to extract user data: %v", err)
+	}
+
+	return true, userData, nil
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotID int64 = 7342037359
+
+// signInitData формирует initData и подписывает ее так же, как Telegram:
+// строка "<bot_id>:WebAppData\n" и отсортированные поля "key=value" через перевод строки, без hash и signature
+func signInitData(t *testing.T, privateKey ed25519.PrivateKey, botID int64, fields map[string]string) string {
+	t.Helper()
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	values := url.Values{}
+	for _, key := range keys {
+		lines = append(lines, key+"="+fields[key])
+		values.Set(key, fields[key])
+	}
+
+	message := strconv.FormatInt(botID, 10) + ":WebAppData\n" + strings.Join(lines, "\n")
+	values.Set("signature", base64.RawURLEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(message))))
+	values.Set("hash", "0000")
+	return values.Encode()
+}
+
+// initDataFields возвращает поля initData с указанным auth_date
+func initDataFields(authDate time.Time) map[string]string {
+	return map[string]string{
+		"auth_date": strconv.FormatInt(authDate.Unix(), 10),
+		"query_id":  "AAHdF6IQAAAAAN0XohDhrOrc",
+		"user":      `{"id":279058397,"first_name":"Vladislav","username":"vdkfrost"}`,
+	}
+}
+
+func TestValidateInitDataSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key pair: %v", err)
+	}
+
+	ta := &TelegramAuth{BotID: testBotID, PublicKey: publicKey, MaxAge: time.Hour}
+	now := time.Now()
+
+	// Поле user изменено после подписи
+	tampered, err := url.ParseQuery(signInitData(t, privateKey, testBotID, initDataFields(now)))
+	if err != nil {
+		t.Fatalf("failed to parse init data: %v", err)
+	}
+	tampered.Set("user", `{"id":1,"first_name":"Admin"}`)
+
+	tests := []struct {
+		name     string
+		initData string
+		valid    bool
+	}{
+		{
+			name:     "valid signature",
+			initData: signInitData(t, privateKey, testBotID, initDataFields(now)),
+			valid:    true,
+		},
+		{
+			name:     "tampered field",
+			initData: tampered.Encode(),
+		},
+		{
+			name:     "wrong bot_id",
+			initData: signInitData(t, privateKey, testBotID+1, initDataFields(now)),
+		},
+		{
+			name:     "stale auth_date",
+			initData: signInitData(t, privateKey, testBotID, initDataFields(now.Add(-2*time.Hour))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, userData, err := ta.ValidateInitDataSignature(tt.initData)
+			if valid != tt.valid {
+				t.Fatalf("valid = %v, want %v (err: %v)", valid, tt.valid, err)
+			}
+
+			if !tt.valid {
+				if err == nil {
+					t.Fatal("expected an error for invalid init data")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if userData.ID != 279058397 || userData.Username != "vdkfrost" {
+				t.Fatalf("unexpected user data: %+v", userData)
+			}
+		})
+	}
+}
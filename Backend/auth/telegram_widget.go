@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ValidateLoginWidget проверяет данные Telegram Login Widget, используемого для входа
+// в браузере вне Telegram. В отличие от initData WebApp, поля пользователя передаются
+// на верхнем уровне, а секретный ключ - это SHA-256 от токена бота.
+func (ta *TelegramAuth) ValidateLoginWidget(data url.Values) (bool, *TelegramWebAppData, error) {
+	hash := data.Get("hash")
+	if hash == "" {
+		return false, nil, fmt.Errorf("hash not found in login data")
+	}
+
+	// Проверяем время аутентификации (не старше MaxAge)
+	authDateStr := data.Get("auth_date")
+	if authDateStr == "" {
+		return false, nil, fmt.Errorf("auth_date not found in login data")
+	}
+
+	if err := ta.checkAuthDate(authDateStr); err != nil {
+		return false, nil, err
+	}
+
+	// Генерируем секретный ключ и хеш для проверки
+	secretKey := sha256.Sum256([]byte(ta.Token))
+	generatedHash := hex.EncodeToString(hmacSHA256([]byte(buildDataCheckString(data)), secretKey[:]))
+
+	// Сравниваем хеши
+	if !hmac.Equal([]byte(generatedHash), []byte(hash)) {
+		return false, nil, fmt.Errorf("invalid hash")
+	}
+
+	id, err := strconv.ParseInt(data.Get("id"), 10, 64)
+	if err != nil {
+		return false, nil, fmt.Errorf("invalid user id: %v", err)
+	}
+
+	userData := &TelegramWebAppData{
+		ID:        id,
+		FirstName: data.Get("first_name"),
+		LastName:  data.Get("last_name"),
+		Username:  data.Get("username"),
+		PhotoURL:  data.Get("photo_url"),
+		AuthDate:  authDateStr,
+		Hash:      hash,
+	}
+
+	return true, userData, nil
+}
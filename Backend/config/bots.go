@@ -0,0 +1,180 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultTenantID ID бота (арендатора) для конфигурации с одним ботом из TELEGRAM_BOT_TOKEN.
+// Пользователи и сессии, созданные до появления нескольких ботов, относятся к нему.
+const DefaultTenantID = "default"
+
+// TelegramBot бот Telegram, через которого пользователи входят в приложение.
+// ID бота используется как ID арендатора: пользователи и сессии разных ботов не пересекаются.
+type TelegramBot struct {
+	ID    string `json:"id"`
+	Token string `json:"token"`
+	// ID бота в Telegram для проверки initData по подписи, по умолчанию берется из токена
+	BotID int64 `json:"bot_id"`
+	// Оформление приложения для клиентов бота
+	Name        string `json:"name"`
+	LogoURL     string `json:"logo_url"`
+	AccentColor string `json:"accent_color"`
+	// Telegram ID пользователей, которые становятся администраторами при входе через этого бота
+	AdminTelegramIDs []int64 `json:"admin_telegram_ids"`
+}
+
+// IsAdmin проверяет, входит ли пользователь в список администраторов бота
+func (b *TelegramBot) IsAdmin(telegramID int64) bool {
+	for _, id := range b.AdminTelegramIDs {
+		if id == telegramID {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	botsOnce sync.Once
+	bots     []*TelegramBot
+	botsErr  error
+
+	tenantIDPattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+)
+
+// LoadTelegramBots загружает ботов из TELEGRAM_BOTS. Вызывается при запуске, чтобы ошибки
+// в настройках обнаруживались сразу, а не при первом входе.
+func LoadTelegramBots() error {
+	_, err := getTelegramBots()
+	return err
+}
+
+// TelegramBots возвращает настроенных ботов
+func TelegramBots() []*TelegramBot {
+	list, _ := getTelegramBots()
+	return list
+}
+
+// GetTelegramBot возвращает бота по ID или nil, если такого бота нет
+func GetTelegramBot(id string) *TelegramBot {
+	for _, bot := range TelegramBots() {
+		if bot.ID == id {
+			return bot
+		}
+	}
+	return nil
+}
+
+// getTelegramBots возвращает загруженных ботов
+func getTelegramBots() ([]*TelegramBot, error) {
+	botsOnce.Do(func() {
+		bots, botsErr = loadTelegramBots(GetConfig())
+	})
+	return bots, botsErr
+}
+
+// IsPlatformAdmin проверяет, входит ли пользователь в ADMIN_TELEGRAM_IDS - список администраторов всех ботов.
+// Только они могут менять настройки, общие для всех ботов, например глобальные роли.
+func IsPlatformAdmin(telegramID int64) bool {
+	// Список проверяется при загрузке ботов, поэтому ошибка разбора здесь невозможна
+	ids, _ := parseTelegramIDs(GetConfig().AdminTelegramIDs)
+	for _, id := range ids {
+		if id == telegramID {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTelegramIDs разбирает список Telegram ID, разделенных запятыми или пробелами
+func parseTelegramIDs(value string) ([]int64, error) {
+	var ids []int64
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// loadTelegramBots разбирает JSON список ботов из TELEGRAM_BOTS. Если список не задан,
+// используется один бот "default" из TELEGRAM_BOT_TOKEN и TELEGRAM_BOT_ID.
+// Администраторы из ADMIN_TELEGRAM_IDS добавляются в список администраторов каждого бота.
+func loadTelegramBots(cfg *Config) ([]*TelegramBot, error) {
+	var list []*TelegramBot
+
+	adminIDs, err := parseTelegramIDs(cfg.AdminTelegramIDs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ADMIN_TELEGRAM_IDS: %v", err)
+	}
+
+	if strings.TrimSpace(cfg.TelegramBots) != "" {
+		if err := json.Unmarshal([]byte(cfg.TelegramBots), &list); err != nil {
+			return nil, fmt.Errorf("invalid TELEGRAM_BOTS: %v", err)
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("TELEGRAM_BOTS is empty")
+		}
+	} else {
+		bot := &TelegramBot{ID: DefaultTenantID, Token: cfg.TelegramBotToken}
+		if cfg.TelegramBotID != "" {
+			id, err := strconv.ParseInt(cfg.TelegramBotID, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid TELEGRAM_BOT_ID: %v", err)
+			}
+			bot.BotID = id
+		}
+		list = append(list, bot)
+	}
+
+	seen := map[string]bool{}
+	for _, bot := range list {
+		if !tenantIDPattern.MatchString(bot.ID) {
+			return nil, fmt.Errorf("invalid bot id %q: use 1-64 lowercase letters, digits, '-' or '_'", bot.ID)
+		}
+		if seen[bot.ID] {
+			return nil, fmt.Errorf("duplicate bot id %q", bot.ID)
+		}
+		seen[bot.ID] = true
+
+		for _, id := range adminIDs {
+			if !bot.IsAdmin(id) {
+				bot.AdminTelegramIDs = append(bot.AdminTelegramIDs, id)
+			}
+		}
+
+		// В режиме разработки initData подписывается токеном бота, поэтому с настоящим токеном
+		// любой мог бы войти под любым пользователем Telegram, в том числе администратором
+		if bot.Token != "" && cfg.DevAuthEnabled {
+			return nil, fmt.Errorf("bot %s has a real token: DEV_AUTH_ENABLED cannot be used with TELEGRAM_BOT_TOKEN or bot tokens", bot.ID)
+		}
+
+		// В режиме разработки боту назначается случайный токен этого экземпляра бэкенда
+		if cfg.DevAuthEnabled {
+			secret := make([]byte, 24)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+			bot.Token = "1:" + hex.EncodeToString(secret)
+			log.Printf("Bot %s uses a random development bot token", bot.ID)
+		}
+
+		if bot.BotID == 0 && bot.Token != "" {
+			id, _, _ := strings.Cut(bot.Token, ":")
+			if parsed, err := strconv.ParseInt(id, 10, 64); err == nil {
+				bot.BotID = parsed
+			}
+		}
+	}
+
+	return list, nil
+}
@@ -2,21 +2,75 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config структура для хранения конфигурации приложения
 type Config struct {
-	ServerPort      string
-	DBHost          string
-	DBPort          string
-	DBUser          string
-	DBPassword      string
-	DBName          string
-	JWTSecret       string
+	ServerPort string
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+	JWTSecret  string
+	// Каталог с PEM ключами подписи JWT, ID ключа подписи, прием старых токенов HS256
+	// и момент (RFC 3339), после которого старые токены не принимаются
+	JWTKeysDir      string
+	JWTSigningKeyID string
+	JWTAcceptLegacy bool
+	JWTLegacyUntil  string
 	SSLCertPath     string
 	SSLKeyPath      string
 	UseHTTPS        bool
-	AdminTelegramID string
+	// Доверенные прокси (IP адреса или CIDR через запятую), от которых принимается X-Forwarded-For.
+	// Пустой список - IP адрес клиента берется из соединения.
+	TrustedProxies string
+
+	TelegramBotToken string
+	// JSON список ботов с токенами, оформлением и администраторами (см. TelegramBot)
+	TelegramBots string
+	// Telegram ID администраторов всех ботов через запятую (ADMIN_TELEGRAM_IDS, а также устаревший ADMIN_TELEGRAM_ID)
+	AdminTelegramIDs string
+	// Способ проверки initData ("hash" - по токену бота, "signature" - по подписи Ed25519),
+	// ID бота и публичный ключ Telegram в hex для проверки подписи
+	TelegramInitDataValidation string
+	TelegramBotID              string
+	TelegramPublicKey          string
+	// Максимальный возраст данных аутентификации Telegram и хранилище для защиты от их повторного
+	// использования ("memory" - только в памяти экземпляра, "postgres" - дополнительно в базе данных)
+	TelegramAuthMaxAge  time.Duration
+	TelegramReplayStore string
+
+	// Ограничение частоты запросов: хранилище корзин ("memory" или "postgres") и лимиты в формате "N/период"
+	RateLimitEnabled       bool
+	RateLimitStore         string
+	RateLimitGlobal        string
+	RateLimitAuth          string
+	RateLimitSessionCreate string
+	RateLimitJoin          string
+
+	// Режим разработки: вход под вымышленными пользователями Telegram (недоступен в production сборках)
+	DevAuthEnabled bool
+
+	// Время жизни токенов и кеширование проверок отзыва входа и версии прав
+	AccessTokenTTL     time.Duration
+	RefreshTokenTTL    time.Duration
+	LoginCheckCacheTTL time.Duration
+	PermissionCacheTTL time.Duration
+
+	// Настройки планировщика сессий
+	SchedulerInterval   time.Duration
+	LobbyLeadTime       time.Duration
+	StartNotifyLeadTime time.Duration
+	// Срок хранения удаленных сессий до окончательного удаления
+	SessionRetention time.Duration
+
+	// Ограничение неудачных попыток ввода PIN-кода сессии
+	PinFailureWindow         time.Duration
+	PinMaxFailuresPerUser    int
+	PinMaxFailuresPerSession int
 }
 
 // GetConfig возвращает конфигурацию приложения
@@ -34,11 +88,48 @@ func GetConfig() *Config {
 		DBUser:          getEnv("DB_USER", "user"),
 		DBPassword:      getEnv("DB_PASSWORD", "password"),
 		DBName:          getEnv("DB_NAME", "prophecy"),
-		JWTSecret:       getEnv("JWT_SECRET", "prophecy_jwt_secret_key"),
-		AdminTelegramID: getEnv("ADMIN_TELEGRAM_ID", ""),
+		JWTSecret:       getEnv("JWT_SECRET", ""),
+		JWTKeysDir:      getEnv("JWT_KEYS_DIR", ""),
+		JWTSigningKeyID: getEnv("JWT_SIGNING_KEY_ID", ""),
+		JWTAcceptLegacy: getEnv("JWT_ACCEPT_LEGACY_HS256", "false") == "true",
+		JWTLegacyUntil:  getEnv("JWT_LEGACY_HS256_UNTIL", ""),
 		SSLCertPath:     sslCertPath,
 		SSLKeyPath:      sslCertPath,
 		UseHTTPS:        useHTTPS,
+		TrustedProxies:  getEnv("TRUSTED_PROXIES", ""),
+
+		TelegramBotToken:           getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramBots:               getEnv("TELEGRAM_BOTS", ""),
+		AdminTelegramIDs:           getEnv("ADMIN_TELEGRAM_IDS", "") + "," + getEnv("ADMIN_TELEGRAM_ID", ""),
+		TelegramInitDataValidation: getEnv("TELEGRAM_INIT_DATA_VALIDATION", "hash"),
+		TelegramBotID:              getEnv("TELEGRAM_BOT_ID", ""),
+		// По умолчанию - публичный ключ, которым Telegram подписывает initData в production
+		TelegramPublicKey:   getEnv("TELEGRAM_PUBLIC_KEY", "e7bf03a2fa4602af4580703d88dda5bb59f32ed8b02a56c187fe7d34caed242d"),
+		TelegramAuthMaxAge:  time.Duration(getEnvInt("TELEGRAM_AUTH_MAX_AGE_SECONDS", 86400)) * time.Second,
+		TelegramReplayStore: getEnv("TELEGRAM_REPLAY_STORE", "memory"),
+
+		RateLimitEnabled:       getEnv("RATE_LIMIT_ENABLED", "true") == "true",
+		RateLimitStore:         getEnv("RATE_LIMIT_STORE", "memory"),
+		RateLimitGlobal:        getEnv("RATE_LIMIT_GLOBAL", "600/m"),
+		RateLimitAuth:          getEnv("RATE_LIMIT_AUTH", "20/m"),
+		RateLimitSessionCreate: getEnv("RATE_LIMIT_SESSION_CREATE", "20/h"),
+		RateLimitJoin:          getEnv("RATE_LIMIT_JOIN", "30/m"),
+
+		DevAuthEnabled: devAuthAllowed && getEnv("DEV_AUTH_ENABLED", "false") == "true",
+
+		AccessTokenTTL:     time.Duration(getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute,
+		RefreshTokenTTL:    time.Duration(getEnvInt("REFRESH_TOKEN_TTL_DAYS", 30)) * 24 * time.Hour,
+		LoginCheckCacheTTL: time.Duration(getEnvInt("LOGIN_CHECK_CACHE_SECONDS", 30)) * time.Second,
+		PermissionCacheTTL: time.Duration(getEnvInt("PERMISSION_CACHE_SECONDS", 30)) * time.Second,
+
+		SchedulerInterval:   time.Duration(getEnvPositiveInt("SCHEDULER_INTERVAL_SECONDS", 30)) * time.Second,
+		LobbyLeadTime:       time.Duration(getEnvInt("LOBBY_LEAD_MINUTES", 15)) * time.Minute,
+		StartNotifyLeadTime: time.Duration(getEnvInt("START_NOTIFY_LEAD_MINUTES", 10)) * time.Minute,
+		SessionRetention:    time.Duration(getEnvInt("SESSION_RETENTION_DAYS", 30)) * 24 * time.Hour,
+
+		PinFailureWindow:         time.Duration(getEnvInt("PIN_FAILURE_WINDOW_MINUTES", 15)) * time.Minute,
+		PinMaxFailuresPerUser:    getEnvInt("PIN_MAX_FAILURES_PER_USER", 5),
+		PinMaxFailuresPerSession: getEnvInt("PIN_MAX_FAILURES_PER_SESSION", 50),
 	}
 }
 
@@ -50,6 +141,25 @@ func getEnv(key, defaultVal string) string {
 	return defaultVal
 }
 
+// getEnvInt возвращает целочисленное значение переменной окружения или значение по умолчанию
+func getEnvInt(key string, defaultVal int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultVal
+}
+
+// getEnvPositiveInt получает положительное целое значение переменной окружения.
+// Нулевое или отрицательное значение заменяется значением по умолчанию.
+func getEnvPositiveInt(key string, defaultVal int) int {
+	if value := getEnvInt(key, defaultVal); value > 0 {
+		return value
+	}
+	return defaultVal
+}
+
 // fileExists проверяет, существует ли файл
 func fileExists(filename string) bool {
 	info, err := os.Stat(filename)
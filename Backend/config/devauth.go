@@ -0,0 +1,7 @@
+//go:build !production
+
+package config
+
+// devAuthAllowed разрешает режим разработки для входа под вымышленными пользователями Telegram.
+// В сборках с тегом production режим разработки недоступен независимо от настроек.
+const devAuthAllowed = true
@@ -0,0 +1,6 @@
+//go:build production
+
+package config
+
+// devAuthAllowed запрещает режим разработки в production сборках
+const devAuthAllowed = false
@@ -33,16 +33,29 @@ func InitDB() {
 
 	fmt.Println("Successfully connected to database")
 
-	// Если указан ID администратора, обновляем пользователя в базе данных
-	if cfg.AdminTelegramID != "" {
-		updateAdminUser(cfg.AdminTelegramID)
+	// Пользователи из списков администраторов ботов получают права администратора
+	for _, bot := range config.TelegramBots() {
+		for _, telegramID := range bot.AdminTelegramIDs {
+			updateAdminUser(bot.ID, telegramID)
+		}
 	}
 }
 
-// updateAdminUser обновляет пользователя с указанным Telegram ID как администратора
-func updateAdminUser(telegramID string) {
-	query := `UPDATE telegram_users SET is_admin = TRUE WHERE telegram_id = $1`
-	result, err := DB.Exec(query, telegramID)
+// updateAdminUser обновляет пользователя бота tenantID с указанным Telegram ID как администратора
+// и добавляет выдачу прав в журнал аудита
+func updateAdminUser(tenantID string, telegramID int64) {
+	// Версия прав увеличивается, а запись в журнале появляется, только если пользователь еще не был админом,
+	// чтобы его токены перевыпускались с новыми правами
+	query := `
+		WITH promoted AS (
+			UPDATE telegram_users SET is_admin = TRUE, perm_version = perm_version + 1
+			WHERE tenant_id = $1 AND telegram_id = $2 AND NOT is_admin
+			RETURNING id
+		)
+		INSERT INTO audit_log (tenant_id, actor_type, action, target_type, target_id, before, after)
+		SELECT $1, 'system', 'user.grant_admin', 'user', id::text, '{"is_admin": false}', '{"is_admin": true}'
+		FROM promoted`
+	result, err := DB.Exec(query, tenantID, telegramID)
 	if err != nil {
 		log.Printf("Failed to update admin user: %v", err)
 		return
@@ -55,8 +68,8 @@ func updateAdminUser(telegramID string) {
 	}
 
 	if rowsAffected > 0 {
-		fmt.Printf("Successfully updated user with Telegram ID %s as admin\n", telegramID)
+		fmt.Printf("Successfully updated user with Telegram ID %d of bot %s as admin\n", telegramID, tenantID)
 	} else {
-		fmt.Printf("No user found with Telegram ID %s\n", telegramID)
+		fmt.Printf("No user without admin rights found with Telegram ID %d of bot %s\n", telegramID, tenantID)
 	}
 }
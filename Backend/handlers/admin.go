@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"prophecy/backend/auth"
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getTargetUserFromParam получает пользователя текущего бота по параметру id из URL.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func getTargetUserFromParam(c *gin.Context) (*models.TelegramUser, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, false
+	}
+
+	target, err := getTenantUser(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+		return nil, false
+	}
+
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+
+	return target, true
+}
+
+// GrantUserAdmin выдает пользователю права администратора бота (требуется право users.manage_admins)
+func GrantUserAdmin(c *gin.Context) {
+	target, ok := getTargetUserFromParam(c)
+	if !ok {
+		return
+	}
+
+	// Пользователи сервисных аккаунтов не могут быть администраторами
+	if target.TelegramID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Service account users cannot be admins"})
+		return
+	}
+
+	if target.IsAdmin {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already an admin"})
+		return
+	}
+
+	// Заблокированный пользователь не может стать администратором, пока блокировка не снята
+	suspension, err := models.GetActiveUserSuspension(target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check user suspension"})
+		return
+	}
+
+	if suspension != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Suspended users cannot be granted admin rights, lift the suspension first"})
+		return
+	}
+
+	if err := models.SetUserAdmin(target.ID, true); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant admin rights"})
+		return
+	}
+
+	// Новые права начинают действовать сразу, токены пользователя будут перевыпущены
+	auth.ForgetUserPermissions(target.ID)
+
+	recordAudit(c, models.AuditUserGrantAdmin, models.AuditTargetUser, target.ID, gin.H{"is_admin": false}, gin.H{"is_admin": true})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Admin rights granted successfully",
+		"user_id":  target.ID,
+		"is_admin": true,
+	})
+}
+
+// RevokeUserAdmin снимает с пользователя права администратора бота (требуется право users.manage_admins).
+// Нельзя снять права с последнего администратора и с пользователя из списка администраторов бота,
+// который снова получил бы права при следующем входе.
+func RevokeUserAdmin(c *gin.Context) {
+	target, ok := getTargetUserFromParam(c)
+	if !ok {
+		return
+	}
+
+	if bot := config.GetTelegramBot(target.TenantID); bot != nil && bot.IsAdmin(target.TelegramID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is in the bot admin list (ADMIN_TELEGRAM_IDS or admin_telegram_ids) and cannot be revoked"})
+		return
+	}
+
+	revoked, err := models.RevokeUserAdmin(target.TenantID, target.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrLastAdmin) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot revoke the last admin"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke admin rights"})
+		return
+	}
+
+	if !revoked {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is not an admin"})
+		return
+	}
+
+	auth.ForgetUserPermissions(target.ID)
+
+	recordAudit(c, models.AuditUserRevokeAdmin, models.AuditTargetUser, target.ID, gin.H{"is_admin": true}, gin.H{"is_admin": false})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Admin rights revoked successfully",
+		"user_id":  target.ID,
+		"is_admin": false,
+	})
+}
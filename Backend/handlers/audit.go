@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"prophecy/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAuditEntry создает запись журнала аудита о действии над объектом в текущем запросе.
+// Исполнитель - пользователь или сервисный аккаунт из контекста, а для запросов без аутентификации - бэкенд.
+func newAuditEntry(c *gin.Context, action, targetType string, targetID interface{}) *models.AuditEntry {
+	entry := &models.AuditEntry{
+		TenantID:   currentTenantID(c),
+		ActorType:  models.AuditActorSystem,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   fmt.Sprint(targetID),
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}
+
+	if userID, exists := c.Get("user_id"); exists {
+		id := userID.(int)
+		entry.ActorID = &id
+		entry.ActorType = models.AuditActorUser
+		if _, isServiceAccount := c.Get("service_account_id"); isServiceAccount {
+			entry.ActorType = models.AuditActorServiceAccount
+		}
+	}
+
+	return entry
+}
+
+// saveAuditEntry сохраняет запись журнала аудита с состоянием объекта до и после действия (nil, если состояния нет).
+// Действие к этому моменту уже выполнено, поэтому ошибка записи только логируется.
+func saveAuditEntry(entry *models.AuditEntry, before, after interface{}) {
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			log.Printf("Failed to encode audit entry %s: %v", entry.Action, err)
+			return
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			log.Printf("Failed to encode audit entry %s: %v", entry.Action, err)
+			return
+		}
+	}
+
+	if err := models.CreateAuditEntry(entry); err != nil {
+		log.Printf("Failed to record audit entry %s %s/%s: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
+
+// recordPlatformAudit добавляет в журнал аудита действие над объектом, общим для всех ботов.
+// Запись сохраняется с AuditPlatformTenantID и видна в журнале каждого бота.
+func recordPlatformAudit(c *gin.Context, action, targetType string, targetID interface{}, before, after interface{}) {
+	entry := newAuditEntry(c, action, targetType, targetID)
+	entry.TenantID = models.AuditPlatformTenantID
+	saveAuditEntry(entry, before, after)
+}
+
+// recordAudit добавляет в журнал аудита привилегированное действие текущего пользователя над объектом
+func recordAudit(c *gin.Context, action, targetType string, targetID interface{}, before, after interface{}) {
+	saveAuditEntry(newAuditEntry(c, action, targetType, targetID), before, after)
+}
+
+// GetAuditLog получает журнал аудита текущего бота с фильтрами actor_id, action, target_type, target_id,
+// since, until и пагинацией (требуется право audit.view)
+func GetAuditLog(c *gin.Context) {
+	limit, offset := parsePagination(c, 50)
+	filter := models.AuditFilter{
+		TenantID:   currentTenantID(c),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	if actorParam := c.Query("actor_id"); actorParam != "" {
+		actorID, err := strconv.Atoi(actorParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actor ID"})
+			return
+		}
+		filter.ActorID = actorID
+	}
+
+	for param, target := range map[string]**time.Time{
+		"since": &filter.Since,
+		"until": &filter.Until,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + ". Use RFC 3339 or YYYY-MM-DD"})
+			return
+		}
+		*target = &parsed
+	}
+
+	entries, total, err := models.ListAuditEntries(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audit log"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.JSON(http.StatusOK, entries)
+}
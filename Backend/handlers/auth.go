@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
-	"os"
+	"net/url"
 	"strconv"
 
 	"prophecy/backend/auth"
+	"prophecy/backend/config"
 	"prophecy/backend/models"
 	"prophecy/backend/names"
 
@@ -17,6 +22,12 @@ type TelegramAuthRequest struct {
 	InitData string `json:"initData" binding:"required"`
 }
 
+// TelegramBotHeader заголовок запроса с ID бота, через которого входит пользователь
+const TelegramBotHeader = "X-Telegram-Bot"
+
+// errBotNotConfigured возвращается, если бот не настроен для выбранного способа проверки
+var errBotNotConfigured = errors.New("bot is not configured")
+
 // ValidateTelegramToken проверяет токен Telegram WebApp
 func ValidateTelegramToken(c *gin.Context) {
 	var req TelegramAuthRequest
@@ -27,31 +38,157 @@ func ValidateTelegramToken(c *gin.Context) {
 		return
 	}
 
-	// Создание экземпляра TelegramAuth
-	// В production лучше хранить токен в переменных окружения
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if botToken == "" {
-		// Для тестирования используем заглушку
-		botToken = "123456789:ABCDEFabcdef1234567890ABCDEFabcd"
+	// Проверка initData по токену бота или по подписи Telegram, в зависимости от настроек
+	useSignature := config.GetConfig().TelegramInitDataValidation == "signature"
+	authenticateTelegram(c, func(bot *config.TelegramBot) (bool, *auth.TelegramWebAppData, error) {
+		if useSignature {
+			telegramAuth, err := newTelegramSignatureAuth(bot)
+			if err != nil {
+				return false, nil, err
+			}
+			return telegramAuth.ValidateInitDataSignature(req.InitData)
+		}
+
+		telegramAuth, err := newTelegramAuth(bot)
+		if err != nil {
+			return false, nil, err
+		}
+		return telegramAuth.ValidateInitData(req.InitData)
+	})
+}
+
+// ValidateTelegramLoginWidget проверяет данные Telegram Login Widget для входа в браузере вне Telegram
+func ValidateTelegramLoginWidget(c *gin.Context) {
+	// Виджет передает поля пользователя объектом, значения приводим к строкам для проверки подписи
+	var req map[string]interface{}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login data"})
+		return
+	}
+
+	data := url.Values{}
+	for key, value := range req {
+		switch v := value.(type) {
+		case string:
+			data.Set(key, v)
+		case json.Number:
+			data.Set(key, v.String())
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login data field: " + key})
+			return
+		}
 	}
 
-	telegramAuth := auth.NewTelegramAuth(botToken)
+	authenticateTelegram(c, func(bot *config.TelegramBot) (bool, *auth.TelegramWebAppData, error) {
+		telegramAuth, err := newTelegramAuth(bot)
+		if err != nil {
+			return false, nil, err
+		}
+		return telegramAuth.ValidateLoginWidget(data)
+	})
+}
+
+// selectTelegramBots возвращает ботов, для которых проверяются данные входа: бота из пути
+// (/auth/bots/:bot/...), из заголовка X-Telegram-Bot или, если бот не указан, всех настроенных ботов.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func selectTelegramBots(c *gin.Context) ([]*config.TelegramBot, bool) {
+	botID := c.Param("bot")
+	if botID == "" {
+		botID = c.GetHeader(TelegramBotHeader)
+	}
 
-	// Проверка токена с использованием initData
-	valid, userData, err := telegramAuth.ValidateInitData(req.InitData)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Invalid token",
-			"message": err.Error(),
-		})
+	if botID == "" {
+		return config.TelegramBots(), true
+	}
+
+	bot := config.GetTelegramBot(botID)
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bot not found"})
+		return nil, false
+	}
+	return []*config.TelegramBot{bot}, true
+}
+
+// authenticateTelegram проверяет данные входа для выбранных ботов и завершает вход через первого бота,
+// для которого проверка прошла успешно
+func authenticateTelegram(c *gin.Context, validate func(bot *config.TelegramBot) (bool, *auth.TelegramWebAppData, error)) {
+	bots, ok := selectTelegramBots(c)
+	if !ok {
 		return
 	}
 
-	if !valid {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Invalid token",
-			"message": "Token validation failed",
-		})
+	var lastErr error
+	configured := 0
+	for _, bot := range bots {
+		valid, userData, err := validate(bot)
+		if errors.Is(err, errBotNotConfigured) {
+			log.Printf("Telegram authentication is misconfigured: %v", err)
+			continue
+		}
+		configured++
+
+		if err == nil && valid {
+			completeTelegramLogin(c, bot, userData)
+			return
+		}
+		lastErr = err
+	}
+
+	if configured == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Telegram authentication is misconfigured"})
+		return
+	}
+
+	message := "Token validation failed"
+	if lastErr != nil {
+		message = lastErr.Error()
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error":   "Invalid token",
+		"message": message,
+	})
+}
+
+// newTelegramAuth создает экземпляр TelegramAuth с токеном бота
+func newTelegramAuth(bot *config.TelegramBot) (*auth.TelegramAuth, error) {
+	if bot.Token == "" {
+		return nil, fmt.Errorf("%w: bot %s has no token", errBotNotConfigured, bot.ID)
+	}
+
+	return auth.NewTelegramAuth(bot.Token), nil
+}
+
+// newTelegramSignatureAuth создает экземпляр TelegramAuth для проверки initData по подписи Ed25519
+func newTelegramSignatureAuth(bot *config.TelegramBot) (*auth.TelegramAuth, error) {
+	publicKey, err := auth.ParseTelegramPublicKey(config.GetConfig().TelegramPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBotNotConfigured, err)
+	}
+
+	if bot.BotID == 0 {
+		return nil, fmt.Errorf("%w: bot %s has no bot_id", errBotNotConfigured, bot.ID)
+	}
+
+	return auth.NewTelegramSignatureAuth(bot.BotID, publicKey), nil
+}
+
+// completeTelegramLogin завершает вход по проверенным данным Telegram: находит или создает
+// пользователя бота и выдает токены. Вход через WebApp и через Login Widget приводит к одному пользователю.
+func completeTelegramLogin(c *gin.Context, bot *config.TelegramBot, userData *auth.TelegramWebAppData) {
+	// Одни и те же данные аутентификации можно использовать только один раз
+	if err := auth.ClaimInitData(userData); err != nil {
+		if errors.Is(err, auth.ErrInitDataReplayed) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Init data has already been used",
+				"code":  "init_data_replayed",
+			})
+			return
+		}
+		log.Printf("Failed to check init data replay: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
 		return
 	}
 
@@ -65,8 +202,8 @@ func ValidateTelegramToken(c *gin.Context) {
 	// Генерация случайного имени
 	generatedName := names.GenerateRandomName()
 
-	// Проверка, существует ли пользователь Telegram в базе данных
-	telegramUser, err := models.GetTelegramUserByTelegramID(userData.ID)
+	// Проверка, существует ли пользователь Telegram этого бота в базе данных
+	telegramUser, err := models.GetTelegramUserByTelegramID(bot.ID, userData.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to retrieve user",
@@ -79,12 +216,14 @@ func ValidateTelegramToken(c *gin.Context) {
 	if telegramUser == nil {
 		telegramUser = &models.TelegramUser{
 			TelegramID:    userData.ID,
+			TenantID:      bot.ID,
 			FirstName:     userData.FirstName,
 			LastName:      userData.LastName,
 			Username:      userData.Username,
 			PhotoURL:      userData.PhotoURL,
 			AuthDate:      authDateInt,
 			GeneratedName: generatedName,
+			IsAdmin:       bot.IsAdmin(userData.ID),
 		}
 
 		err = models.CreateTelegramUser(telegramUser)
@@ -95,10 +234,50 @@ func ValidateTelegramToken(c *gin.Context) {
 			})
 			return
 		}
+
+		if telegramUser.IsAdmin {
+			recordAdminBootstrap(c, bot, telegramUser.ID)
+		}
+	} else if !telegramUser.IsAdmin && bot.IsAdmin(userData.ID) {
+		// Пользователь добавлен в список администраторов бота после первого входа
+		if err := models.SetUserAdmin(telegramUser.ID, true); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+		auth.ForgetUserPermissions(telegramUser.ID)
+		recordAdminBootstrap(c, bot, telegramUser.ID)
+
+		if telegramUser, err = models.GetTelegramUserByID(telegramUser.ID); err != nil || telegramUser == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+			return
+		}
 	}
 
-	// Генерация JWT токена
-	token, err := auth.GenerateJWT(telegramUser)
+	// Заблокированный пользователь не получает токенов и видит причину и срок блокировки
+	if !ensureNotSuspended(c, telegramUser.ID) {
+		return
+	}
+
+	// Добавляем пользователя в сессии, в списки которых он был импортирован заранее
+	if sessionIDs, err := models.ClaimRosterEntries(telegramUser.ID, userData.ID, userData.Username); err != nil {
+		log.Printf("Failed to claim roster entries for user %d: %v", telegramUser.ID, err)
+	} else if len(sessionIDs) > 0 {
+		fmt.Printf("User %d joined sessions %v from imported rosters\n", telegramUser.ID, sessionIDs)
+	}
+
+	respondWithLogin(c, telegramUser)
+}
+
+// recordAdminBootstrap добавляет в журнал аудита выдачу прав администратора пользователю из списка администраторов бота
+func recordAdminBootstrap(c *gin.Context, bot *config.TelegramBot, userID int) {
+	entry := newAuditEntry(c, models.AuditUserGrantAdmin, models.AuditTargetUser, userID)
+	entry.TenantID = bot.ID
+	saveAuditEntry(entry, gin.H{"is_admin": false}, gin.H{"is_admin": true})
+}
+
+// respondWithLogin создает вход пользователя и отправляет клиенту пару токенов вместе с данными пользователя
+func respondWithLogin(c *gin.Context, telegramUser *models.TelegramUser) {
+	tokens, err := auth.IssueLogin(telegramUser, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to generate token",
@@ -109,32 +288,28 @@ func ValidateTelegramToken(c *gin.Context) {
 
 	// Если токен валиден, возвращаем успех с JWT токеном
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Token is valid",
-		"token":   token,
-		"user": gin.H{
-			"id":             telegramUser.ID,
-			"telegram_id":    telegramUser.TelegramID,
-			"first_name":     telegramUser.FirstName,
-			"last_name":      telegramUser.LastName,
-			"generated_name": telegramUser.GeneratedName,
-			"is_admin":       telegramUser.IsAdmin,
-			"photo_url":      telegramUser.PhotoURL,
-			"auth_date":      telegramUser.AuthDate,
-			"created_at":     telegramUser.CreatedAt,
-		},
+		"message":       "Token is valid",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          userResponse(telegramUser),
 	})
 }
 
-// GetTelegramBotToken возвращает токен бота (для тестирования)
-func GetTelegramBotToken(c *gin.Context) {
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if botToken == "" {
-		botToken = "TELEGRAM_BOT_TOKEN_NOT_SET"
+// userResponse возвращает данные пользователя для ответов аутентификации
+func userResponse(telegramUser *models.TelegramUser) gin.H {
+	return gin.H{
+		"id":             telegramUser.ID,
+		"telegram_id":    telegramUser.TelegramID,
+		"tenant_id":      telegramUser.TenantID,
+		"first_name":     telegramUser.FirstName,
+		"last_name":      telegramUser.LastName,
+		"generated_name": telegramUser.GeneratedName,
+		"is_admin":       telegramUser.IsAdmin,
+		"photo_url":      telegramUser.PhotoURL,
+		"auth_date":      telegramUser.AuthDate,
+		"created_at":     telegramUser.CreatedAt,
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"bot_token": botToken,
-	})
 }
 
 // VerifyJWT проверяет JWT токен и возвращает информацию о пользователе
@@ -154,7 +329,7 @@ func VerifyJWT(c *gin.Context) {
 	}
 
 	// Получение информации о пользователе из базы данных
-	telegramUser, err := models.GetTelegramUserByTelegramID(jwtClaims.TelegramID)
+	telegramUser, err := models.GetTelegramUserByID(jwtClaims.UserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user", "message": err.Error()})
 		return
@@ -167,17 +342,7 @@ func VerifyJWT(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Token is valid",
-		"user": gin.H{
-			"id":             telegramUser.ID,
-			"telegram_id":    telegramUser.TelegramID,
-			"first_name":     telegramUser.FirstName,
-			"last_name":      telegramUser.LastName,
-			"generated_name": telegramUser.GeneratedName,
-			"is_admin":       telegramUser.IsAdmin,
-			"photo_url":      telegramUser.PhotoURL,
-			"auth_date":      telegramUser.AuthDate,
-			"created_at":     telegramUser.CreatedAt,
-		},
+		"user":    userResponse(telegramUser),
 	})
 }
 
@@ -194,3 +359,44 @@ func CheckAdminStatus(c *gin.Context) {
 		"is_admin": isAdmin,
 	})
 }
+
+// GetTelegramBots возвращает оформление настроенных ботов для клиентов
+func GetTelegramBots(c *gin.Context) {
+	bots := []gin.H{}
+	for _, bot := range config.TelegramBots() {
+		bots = append(bots, botResponse(bot))
+	}
+	c.JSON(http.StatusOK, bots)
+}
+
+// GetTelegramBot возвращает оформление бота по параметру bot из URL
+func GetTelegramBot(c *gin.Context) {
+	bot := config.GetTelegramBot(c.Param("bot"))
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bot not found"})
+		return
+	}
+	c.JSON(http.StatusOK, botResponse(bot))
+}
+
+// botResponse возвращает открытые данные бота без токена и списка администраторов
+func botResponse(bot *config.TelegramBot) gin.H {
+	return gin.H{
+		"id":           bot.ID,
+		"name":         bot.Name,
+		"logo_url":     bot.LogoURL,
+		"accent_color": bot.AccentColor,
+	}
+}
+
+// GetJWKS возвращает открытые ключи проверки JWT токенов в формате JWKS
+func GetJWKS(c *gin.Context) {
+	keys, err := auth.JWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load signing keys"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"prophecy/backend/models"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkNotBanned проверяет, что у пользователя нет действующего бана в сессии.
+// Если пользователь забанен или произошла ошибка, ответ уже отправлен клиенту и возвращается false.
+func checkNotBanned(c *gin.Context, sessionID, userID int) bool {
+	ban, err := models.GetActiveSessionBan(sessionID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session ban"})
+		return false
+	}
+
+	if ban != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      "User is banned from this session",
+			"reason":     ban.Reason,
+			"expires_at": ban.ExpiresAt,
+		})
+		return false
+	}
+
+	return true
+}
+
+// BanSessionPlayer банит пользователя в сессии и исключает его из неё
+func BanSessionPlayer(c *gin.Context) {
+	user, session, ok := loadSessionForAction(c, policy.SessionModerate)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		UserID        int        `json:"user_id" binding:"required"`
+		Reason        string     `json:"reason"`
+		ExpiresAt     *time.Time `json:"expires_at"`
+		DurationHours int        `json:"duration_hours"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if requestData.UserID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot ban yourself"})
+		return
+	}
+
+	if requestData.UserID == session.ArchitectID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session architect cannot be banned"})
+		return
+	}
+
+	// Бан без срока действует бессрочно
+	expiresAt := requestData.ExpiresAt
+	if expiresAt == nil && requestData.DurationHours > 0 {
+		t := time.Now().Add(time.Duration(requestData.DurationHours) * time.Hour)
+		expiresAt = &t
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiration time must be in the future"})
+		return
+	}
+
+	target, err := getTenantUser(c, requestData.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+		return
+	}
+
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Участников с особыми ролями может забанить только создатель сессии или админ
+	canGrantRoles, ok := checkPermission(c, user, policy.SessionGrantRoles, session)
+	if !ok {
+		return
+	}
+
+	if !canGrantRoles {
+		role, err := models.GetSessionMemberRole(session.ID, target.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session membership"})
+			return
+		}
+
+		if role != "" && role != models.SessionRolePlayer {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+	}
+
+	ban := &models.SessionBan{
+		SessionID: session.ID,
+		UserID:    target.ID,
+		Reason:    requestData.Reason,
+		BannedBy:  &user.ID,
+		ExpiresAt: expiresAt,
+		UserName:  target.GeneratedName,
+	}
+
+	if err := models.CreateSessionBan(ban); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ban user"})
+		return
+	}
+
+	recordAudit(c, models.AuditSessionBan, models.AuditTargetSession, session.ID, nil, ban)
+
+	c.JSON(http.StatusCreated, ban)
+}
+
+// GetSessionBans получает список банов сессии (по умолчанию только действующие, all=true - все)
+func GetSessionBans(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionModerate)
+	if !ok {
+		return
+	}
+
+	activeOnly := c.Query("all") != "true"
+
+	bans, err := models.GetSessionBans(session.ID, activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session bans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, bans)
+}
+
+// LiftSessionBan снимает бан пользователя в сессии
+func LiftSessionBan(c *gin.Context) {
+	user, session, ok := loadSessionForAction(c, policy.SessionModerate)
+	if !ok {
+		return
+	}
+
+	bannedUserID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	lifted, err := models.LiftSessionBan(session.ID, bannedUserID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lift ban"})
+		return
+	}
+
+	if !lifted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ban not found"})
+		return
+	}
+
+	recordAudit(c, models.AuditSessionLiftBan, models.AuditTargetSession, session.ID, gin.H{"user_id": bannedUserID}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ban lifted successfully"})
+}
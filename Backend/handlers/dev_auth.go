@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"prophecy/backend/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateDevInitData выдает подписанную initData для вымышленного пользователя Telegram.
+// Доступно только в режиме разработки: полученные данные принимаются POST /auth/telegram.
+func CreateDevInitData(c *gin.Context) {
+	var user auth.DevTelegramUser
+	if err := c.ShouldBindJSON(&user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if user.ID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID must be positive"})
+		return
+	}
+
+	// Данные подписываются токеном выбранного бота, а если бот не указан - первого настроенного бота
+	bots, ok := selectTelegramBots(c)
+	if !ok {
+		return
+	}
+
+	telegramAuth, err := newTelegramAuth(bots[0])
+	if err != nil {
+		log.Printf("Telegram authentication is misconfigured: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Telegram authentication is misconfigured"})
+		return
+	}
+
+	initData, err := telegramAuth.SignInitData(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign init data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"initData": initData})
+}
@@ -1,14 +1,102 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
+	"prophecy/backend/config"
 	"prophecy/backend/models"
+	"prophecy/backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
 
+// bindOptionalJSON парсит необязательное JSON тело запроса, пустое тело не считается ошибкой.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func bindOptionalJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// parsePagination получает параметры пагинации limit и offset из запроса.
+// Некорректные значения заменяются значениями по умолчанию, limit ограничен сотней.
+func parsePagination(c *gin.Context, defaultLimit int) (int, int) {
+	limit := defaultLimit
+	offset := 0
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	if offsetParam := c.Query("offset"); offsetParam != "" {
+		if parsedOffset, err := strconv.Atoi(offsetParam); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+
+	// Ограничение максимального значения limit для предотвращения перегрузки
+	if limit > 100 {
+		limit = 100
+	}
+
+	return limit, offset
+}
+
+// currentTenantID возвращает ID бота (арендатора) пользователя, выполняющего запрос
+func currentTenantID(c *gin.Context) string {
+	if tenantID := c.GetString("tenant_id"); tenantID != "" {
+		return tenantID
+	}
+	return config.DefaultTenantID
+}
+
+// getTenantUser получает пользователя по ID. Пользователи других ботов считаются несуществующими.
+func getTenantUser(c *gin.Context, userID int) (*models.TelegramUser, error) {
+	user, err := models.GetTelegramUserByID(userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	if user.TenantID != currentTenantID(c) {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// checkPermission проверяет право пользователя на ресурс через политику доступа.
+// При ошибке ответ уже отправлен клиенту и второй результат равен false.
+func checkPermission(c *gin.Context, user *models.TelegramUser, action policy.Action, resource interface{}) (bool, bool) {
+	allowed, err := policy.Can(user, action, resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+		return false, false
+	}
+	return allowed, true
+}
+
+// authorize требует право пользователя на ресурс.
+// При отказе или ошибке ответ уже отправлен клиенту и возвращается false.
+func authorize(c *gin.Context, user *models.TelegramUser, action policy.Action, resource interface{}) bool {
+	allowed, ok := checkPermission(c, user, action, resource)
+	if !ok {
+		return false
+	}
+
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return false
+	}
+
+	return true
+}
+
 // HealthCheck возвращает статус работоспособности приложения
 func HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
@@ -83,28 +171,10 @@ func CreateUser(c *gin.Context) {
 // GetAllUsers возвращает список всех пользователей Telegram с пагинацией
 func GetAllUsers(c *gin.Context) {
 	// Получение параметров пагинации из запроса
-	limit := 10 // значение по умолчанию
-	offset := 0 // значение по умолчанию
-
-	if limitParam := c.Query("limit"); limitParam != "" {
-		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	if offsetParam := c.Query("offset"); offsetParam != "" {
-		if parsedOffset, err := strconv.Atoi(offsetParam); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
-	}
-
-	// Ограничение максимального значения limit для предотвращения перегрузки
-	if limit > 100 {
-		limit = 100
-	}
+	limit, offset := parsePagination(c, 10)
 
-	// Получение пользователей из базы данных с пагинацией
-	users, err := models.GetAllTelegramUsers(limit, offset)
+	// Получение пользователей бота из базы данных с пагинацией
+	users, err := models.GetAllTelegramUsers(currentTenantID(c), limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get users"})
 		return
@@ -116,7 +186,7 @@ func GetAllUsers(c *gin.Context) {
 // GetUserStats возвращает статистику пользователей
 func GetUserStats(c *gin.Context) {
 	// Получение статистики из базы данных
-	stats, err := models.GetUserStats()
+	stats, err := models.GetUserStats(currentTenantID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user stats"})
 		return
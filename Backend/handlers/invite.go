@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"prophecy/backend/models"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isInviteError проверяет, связана ли ошибка с недействительным приглашением
+func isInviteError(err error) bool {
+	return errors.Is(err, models.ErrInviteRevoked) ||
+		errors.Is(err, models.ErrInviteExpired) ||
+		errors.Is(err, models.ErrInviteExhausted)
+}
+
+// respondInviteError отправляет ответ о недействительном приглашении
+func respondInviteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInviteRevoked):
+		c.JSON(http.StatusGone, gin.H{"error": "Invite has been revoked"})
+	case errors.Is(err, models.ErrInviteExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Invite has expired"})
+	case errors.Is(err, models.ErrInviteExhausted):
+		c.JSON(http.StatusGone, gin.H{"error": "Invite has reached its maximum number of uses"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check invite"})
+	}
+}
+
+// getInviteFromParam получает приглашение сессии по параметру invite_id из URL.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func getInviteFromParam(c *gin.Context, session *models.Session) (*models.SessionInvite, bool) {
+	inviteID, err := strconv.Atoi(c.Param("invite_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return nil, false
+	}
+
+	invite, err := models.GetSessionInviteByID(inviteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invite"})
+		return nil, false
+	}
+
+	// Приглашение другой сессии считаем несуществующим
+	if invite == nil || invite.SessionID != session.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return nil, false
+	}
+
+	return invite, true
+}
+
+// CreateSessionInvite создает новое приглашение в сессию
+func CreateSessionInvite(c *gin.Context) {
+	user, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	// Парсим данные из запроса
+	var requestData struct {
+		Label          string     `json:"label"`
+		ExpiresAt      *time.Time `json:"expires_at"`
+		ExpiresInHours int        `json:"expires_in_hours"`
+		MaxUses        *int       `json:"max_uses"`
+		DefaultTeam    string     `json:"default_team"`
+		DefaultClan    string     `json:"default_clan"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Срок действия можно задать либо точным временем, либо количеством часов
+	expiresAt := requestData.ExpiresAt
+	if expiresAt == nil && requestData.ExpiresInHours > 0 {
+		t := time.Now().Add(time.Duration(requestData.ExpiresInHours) * time.Hour)
+		expiresAt = &t
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiration time must be in the future"})
+		return
+	}
+
+	if requestData.MaxUses != nil && *requestData.MaxUses <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_uses must be positive"})
+		return
+	}
+
+	// Генерируем код приглашения
+	code, err := generateReferralLink()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invite code"})
+		return
+	}
+
+	invite := &models.SessionInvite{
+		SessionID:   session.ID,
+		Code:        code,
+		Label:       requestData.Label,
+		ExpiresAt:   expiresAt,
+		MaxUses:     requestData.MaxUses,
+		DefaultTeam: requestData.DefaultTeam,
+		DefaultClan: requestData.DefaultClan,
+		CreatedBy:   &user.ID,
+	}
+
+	if err := models.CreateSessionInvite(invite); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invite)
+}
+
+// GetSessionInvites получает список приглашений сессии
+func GetSessionInvites(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	invites, err := models.GetSessionInvites(session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invites"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invites)
+}
+
+// RevokeSessionInvite отзывает приглашение сессии
+func RevokeSessionInvite(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	invite, ok := getInviteFromParam(c, session)
+	if !ok {
+		return
+	}
+
+	if err := models.RevokeSessionInvite(invite.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked successfully"})
+}
+
+// GetSessionInvitePlayers получает игроков, присоединившихся по приглашению
+func GetSessionInvitePlayers(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	invite, ok := getInviteFromParam(c, session)
+	if !ok {
+		return
+	}
+
+	players, err := models.GetInvitePlayers(invite.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invite players"})
+		return
+	}
+
+	c.JSON(http.StatusOK, players)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"prophecy/backend/models"
+	"prophecy/backend/notify"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// joinRequestResult результат обработки одной заявки при массовом решении
+type joinRequestResult struct {
+	RequestID int                 `json:"request_id"`
+	Status    string              `json:"status"`
+	Error     string              `json:"error,omitempty"`
+	Request   *models.JoinRequest `json:"request,omitempty"`
+}
+
+// notifyJoinRequestDecision уведомляет заявителя о решении по его заявке через бота сессии
+func notifyJoinRequestDecision(session *models.Session, request *models.JoinRequest) {
+	var text string
+	if request.Status == models.JoinRequestApproved {
+		text = fmt.Sprintf("Ваша заявка на участие в игре «%s» одобрена", request.SessionName)
+	} else {
+		text = fmt.Sprintf("Ваша заявка на участие в игре «%s» отклонена", request.SessionName)
+	}
+
+	go func() {
+		if err := notify.SendTelegramMessage(session.TenantID, request.TelegramID, text); err != nil {
+			log.Printf("Failed to notify user %d about join request %d: %v", request.UserID, request.ID, err)
+		}
+	}()
+}
+
+// joinRequestErrorMessage возвращает текст ошибки обработки заявки для ответа клиенту
+func joinRequestErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, models.ErrJoinRequestNotFound):
+		return "Join request not found"
+	case errors.Is(err, models.ErrJoinRequestNotPending):
+		return "Join request has already been decided"
+	case errors.Is(err, models.ErrSessionFull):
+		return "Session is full"
+	case errors.Is(err, models.ErrUserBanned):
+		return "User is banned from this session"
+	case errors.Is(err, models.ErrInviteRevoked):
+		return "Invite has been revoked"
+	case errors.Is(err, models.ErrInviteExpired):
+		return "Invite has expired"
+	case errors.Is(err, models.ErrInviteExhausted):
+		return "Invite has reached its maximum number of uses"
+	default:
+		return "Failed to process join request"
+	}
+}
+
+// GetSessionJoinRequests получает заявки на вступление в сессию (по умолчанию только ожидающие)
+func GetSessionJoinRequests(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	status := c.DefaultQuery("status", models.JoinRequestPending)
+	if status == "all" {
+		status = ""
+	} else if status != models.JoinRequestPending && status != models.JoinRequestApproved && status != models.JoinRequestRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Allowed values: 'pending', 'approved', 'rejected', 'all'"})
+		return
+	}
+
+	requests, err := models.GetSessionJoinRequests(session.ID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get join requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// decideJoinRequests одобряет или отклоняет заявки, переданные в теле запроса списком request_ids.
+// Каждая заявка обрабатывается отдельно, результат возвращается по каждой из них.
+func decideJoinRequests(c *gin.Context, approve bool) {
+	user, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		RequestIDs []int `json:"request_ids" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make([]joinRequestResult, 0, len(requestData.RequestIDs))
+	for _, requestID := range requestData.RequestIDs {
+		var request *models.JoinRequest
+		var err error
+		if approve {
+			request, err = models.ApproveJoinRequest(requestID, session.ID, user.ID)
+		} else {
+			request, err = models.RejectJoinRequest(requestID, session.ID, user.ID)
+		}
+
+		if err != nil {
+			results = append(results, joinRequestResult{
+				RequestID: requestID,
+				Status:    "error",
+				Error:     joinRequestErrorMessage(err),
+			})
+			continue
+		}
+
+		notifyJoinRequestDecision(session, request)
+		results = append(results, joinRequestResult{
+			RequestID: requestID,
+			Status:    request.Status,
+			Request:   request,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// ApproveSessionJoinRequests одобряет заявки на вступление в сессию
+func ApproveSessionJoinRequests(c *gin.Context) {
+	decideJoinRequests(c, true)
+}
+
+// RejectSessionJoinRequests отклоняет заявки на вступление в сессию
+func RejectSessionJoinRequests(c *gin.Context) {
+	decideJoinRequests(c, false)
+}
+
+// GetPlayerJoinRequests получает заявки текущего пользователя и их статусы
+func GetPlayerJoinRequests(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	requests, err := models.GetUserJoinRequests(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get join requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
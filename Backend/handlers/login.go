@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"prophecy/backend/auth"
+	"prophecy/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentLoginID возвращает ID входа, которым выполнен запрос (пустая строка для старых токенов)
+func currentLoginID(c *gin.Context) string {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return ""
+	}
+
+	jwtClaims, ok := claims.(*auth.JWTClaims)
+	if !ok {
+		return ""
+	}
+	return jwtClaims.SessionID
+}
+
+// RefreshToken обменивает refresh токен на новую пару токенов
+func RefreshToken(c *gin.Context) {
+	var requestData struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, user, err := auth.RefreshLogin(requestData.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, login has been revoked"})
+		case errors.Is(err, models.ErrRefreshTokenInvalid),
+			errors.Is(err, models.ErrLoginRevoked),
+			errors.Is(err, models.ErrLoginExpired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token", "message": err.Error()})
+		case errors.Is(err, models.ErrUserSuspended):
+			// Блокировка могла быть снята между проверками
+			if ensureNotSuspended(c, user.ID) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "User is suspended"})
+			}
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          userResponse(user),
+	})
+}
+
+// Logout отзывает текущий вход пользователя
+func Logout(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	loginID := currentLoginID(c)
+	if loginID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is not bound to a login"})
+		return
+	}
+
+	if _, err := auth.RevokeLogin(user.ID, loginID, models.LoginRevokedLogout); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// GetLogins получает действующие входы текущего пользователя
+func GetLogins(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	logins, err := models.GetUserLogins(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get logins"})
+		return
+	}
+
+	loginID := currentLoginID(c)
+	for i := range logins {
+		logins[i].Current = logins[i].ID == loginID
+	}
+
+	c.JSON(http.StatusOK, logins)
+}
+
+// RevokeLogin отзывает один из входов текущего пользователя
+func RevokeLogin(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := auth.RevokeLogin(user.ID, c.Param("login_id"), models.LoginRevokedByUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke login"})
+		return
+	}
+
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Login not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Login revoked successfully"})
+}
+
+// RevokeAllLogins отзывает все входы текущего пользователя (keep_current=true - кроме текущего)
+func RevokeAllLogins(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	exceptLoginID := ""
+	if c.Query("keep_current") == "true" {
+		exceptLoginID = currentLoginID(c)
+	}
+
+	count, err := auth.RevokeUserLogins(user.ID, exceptLoginID, models.LoginRevokedByUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke logins"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logins revoked successfully", "revoked": count})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"prophecy/backend/models"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadSessionForAction получает пользователя, сессию из URL и проверяет право пользователя action на нее.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func loadSessionForAction(c *gin.Context, action policy.Action) (*models.TelegramUser, *models.Session, bool) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	session, ok := getSessionFromParam(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	if !authorize(c, user, action, session) {
+		return nil, nil, false
+	}
+
+	return user, session, true
+}
+
+// GetSessionMembers получает всех участников сессии вместе с их ролями
+func GetSessionMembers(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionView)
+	if !ok {
+		return
+	}
+
+	members, err := models.GetSessionMembers(session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// SetSessionMemberRole назначает пользователю роль в сессии (только создатель сессии или админ)
+func SetSessionMemberRole(c *gin.Context) {
+	user, session, ok := loadSessionForAction(c, policy.SessionGrantRoles)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var requestData struct {
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !models.IsValidSessionRole(requestData.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Allowed values: 'co_architect', 'moderator', 'observer', 'player'"})
+		return
+	}
+
+	// Создатель сессии управляет ей сам и не может получить другую роль
+	if memberID == session.ArchitectID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session architect cannot be assigned a role"})
+		return
+	}
+
+	member, err := getTenantUser(c, memberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+		return
+	}
+
+	if member == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	previousRole, err := models.GetSessionMemberRole(session.ID, member.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session membership"})
+		return
+	}
+
+	if err := models.SetSessionMemberRole(session.ID, member.ID, requestData.Role, user.ID); err != nil {
+		if errors.Is(err, models.ErrSessionFull) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Session is full"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set session role"})
+		return
+	}
+
+	recordAudit(c, models.AuditSessionSetMemberRole, models.AuditTargetSession, session.ID,
+		gin.H{"user_id": member.ID, "session_role": previousRole},
+		gin.H{"user_id": member.ID, "session_role": requestData.Role})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Session role updated successfully",
+		"user_id":      member.ID,
+		"session_role": requestData.Role,
+	})
+}
+
+// UpdateSessionPlayer исправляет команду и клан игрока в сессии
+// (создатель сессии, со-архитекторы, модераторы и админы)
+func UpdateSessionPlayer(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionModerate)
+	if !ok {
+		return
+	}
+
+	playerID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var requestData struct {
+		Team *string `json:"team"`
+		Clan *string `json:"clan"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, value := range []*string{requestData.Team, requestData.Clan} {
+		if value != nil && len(*value) > maxRosterTagLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Team and clan must be at most " + strconv.Itoa(maxRosterTagLength) + " characters long"})
+			return
+		}
+	}
+
+	before, after, err := models.UpdatePlayerState(session.ID, playerID, requestData.Team, requestData.Clan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player"})
+		return
+	}
+
+	if after == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player not found in session"})
+		return
+	}
+
+	recordAudit(c, models.AuditSessionUpdatePlayer, models.AuditTargetSession, session.ID,
+		gin.H{"user_id": playerID, "team": before.Team, "clan": before.Clan},
+		gin.H{"user_id": playerID, "team": after.Team, "clan": after.Clan})
+
+	c.JSON(http.StatusOK, gin.H{"user_id": playerID, "team": after.Team, "clan": after.Clan})
+}
+
+// RevokeSessionMemberRole отзывает роль пользователя, исключая его из сессии (только создатель сессии или админ)
+func RevokeSessionMemberRole(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionGrantRoles)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	previousRole, err := models.GetSessionMemberRole(session.ID, memberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session membership"})
+		return
+	}
+
+	if err := models.RemovePlayerFromSession(memberID, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session role"})
+		return
+	}
+
+	if previousRole != "" {
+		recordAudit(c, models.AuditSessionRevokeMember, models.AuditTargetSession, session.ID,
+			gin.H{"user_id": memberID, "session_role": previousRole}, nil)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session role revoked successfully"})
+}
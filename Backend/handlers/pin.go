@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Ограничения длины PIN-кода или кодовой фразы
+const (
+	minPinLength = 4
+	maxPinLength = 64
+)
+
+// hashSessionPin проверяет PIN-код и возвращает его хеш для хранения в базе данных
+func hashSessionPin(pin string) (string, error) {
+	if len(pin) < minPinLength || len(pin) > maxPinLength {
+		return "", fmt.Errorf("PIN must be between %d and %d characters long", minPinLength, maxPinLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// generateSessionPin генерирует случайный PIN-код из шести цифр
+func generateSessionPin() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// checkSessionPin проверяет PIN-код, переданный при вступлении в сессию.
+// Неудачные попытки ограничиваются для пользователя, а при переборе с разных аккаунтов - замедляются для всей сессии.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func checkSessionPin(c *gin.Context, session *models.Session, userID int, pin string) bool {
+	if !session.PinRequired {
+		return true
+	}
+
+	if pin == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "PIN is required to join this session", "pin_required": true})
+		return false
+	}
+
+	cfg := config.GetConfig()
+	windowStart := time.Now().Add(-cfg.PinFailureWindow)
+
+	// Попытка резервируется до сравнения PIN-кода, чтобы параллельные запросы не обошли лимит.
+	// При неверном PIN-коде она остается записанной как неудачная.
+	reserved, err := models.ReservePinAttempt(session.ID, userID, windowStart, cfg.PinMaxFailuresPerUser, cfg.PinMaxFailuresPerSession)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check PIN attempts"})
+		return false
+	}
+
+	if !reserved {
+		c.Header("Retry-After", strconv.Itoa(int(cfg.PinFailureWindow.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed PIN attempts, try again later"})
+		return false
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(session.PinHash), []byte(pin)) != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid PIN", "pin_required": true})
+		return false
+	}
+
+	if err := models.ClearPinFailures(session.ID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record PIN attempt"})
+		return false
+	}
+
+	return true
+}
+
+// RotateSessionPin устанавливает новый PIN-код сессии.
+// Если PIN-код не передан, генерируется случайный. PIN-код возвращается только в этом ответе.
+func RotateSessionPin(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		Pin string `json:"pin"`
+	}
+
+	if !bindOptionalJSON(c, &requestData) {
+		return
+	}
+
+	pin := requestData.Pin
+	if pin == "" {
+		var err error
+		pin, err = generateSessionPin()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PIN"})
+			return
+		}
+	}
+
+	pinHash, err := hashSessionPin(pin)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := models.SetSessionPin(session.ID, pinHash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update PIN"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "PIN updated successfully", "pin": pin})
+}
+
+// RemoveSessionPin снимает защиту сессии PIN-кодом
+func RemoveSessionPin(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	if err := models.SetSessionPin(session.ID, ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove PIN"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "PIN removed successfully"})
+}
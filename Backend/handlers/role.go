@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
+	"prophecy/backend/auth"
+	"prophecy/backend/config"
 	"prophecy/backend/models"
+	"prophecy/backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,9 +34,29 @@ func SetUserRole(c *gin.Context) {
 		return
 	}
 
-	// Проверка допустимых значений роли
-	if requestData.Role != "Архитектор" && requestData.Role != "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Allowed values: 'Архитектор' or empty"})
+	// Назначить можно только существующую глобальную роль, кроме встроенных user и admin
+	if requestData.Role != "" {
+		exists, err := models.RoleExists(models.RoleScopeGlobal, requestData.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check role"})
+			return
+		}
+
+		if !exists || models.IsBuiltinGlobalRole(requestData.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Use GET /admin/roles to list global roles or an empty string to remove the role"})
+			return
+		}
+	}
+
+	// Роль можно установить только пользователю того же бота
+	target, err := getTenantUser(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+		return
+	}
+
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
@@ -42,9 +67,155 @@ func SetUserRole(c *gin.Context) {
 		return
 	}
 
+	// Новые права начинают действовать сразу, токены пользователя будут перевыпущены
+	auth.ForgetUserPermissions(userID)
+
+	recordAudit(c, models.AuditUserSetRole, models.AuditTargetUser, userID, gin.H{"role": target.Role}, gin.H{"role": requestData.Role})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User role updated successfully",
 		"user_id": userID,
 		"role":    requestData.Role,
 	})
 }
+
+// GetRoles получает все роли и их права (только для администраторов)
+func GetRoles(c *gin.Context) {
+	roles, err := models.GetRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// checkPlatformAdmin проверяет, что текущий пользователь - администратор всех ботов из ADMIN_TELEGRAM_IDS.
+// Глобальные роли общие для всех ботов, поэтому администратор одного бота не может их менять.
+// Если это не так, ответ уже отправлен клиенту и возвращается false.
+func checkPlatformAdmin(c *gin.Context) bool {
+	if !config.IsPlatformAdmin(c.GetInt64("telegram_id")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Roles are shared by all bots and can only be changed by admins from ADMIN_TELEGRAM_IDS"})
+		return false
+	}
+	return true
+}
+
+// SaveRole создает глобальную роль или заменяет ее права (только для администраторов из ADMIN_TELEGRAM_IDS).
+// Встроенные роли изменить нельзя: их права действуют во всех ботах.
+func SaveRole(c *gin.Context) {
+	if !checkPlatformAdmin(c) {
+		return
+	}
+
+	name := strings.TrimSpace(c.Param("role"))
+	if name == "" || len(name) > 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role name must be between 1 and 64 characters"})
+		return
+	}
+
+	if !models.IsEditableGlobalRole(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in roles cannot be changed"})
+		return
+	}
+
+	var requestData struct {
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	permissions := []string{}
+	for _, permission := range requestData.Permissions {
+		if !policy.IsKnownAction(permission) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permission: " + permission, "allowed_permissions": policy.Actions})
+			return
+		}
+		if !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	previous, err := models.GetRole(models.RoleScopeGlobal, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get role"})
+		return
+	}
+
+	role := &models.Role{
+		Scope:       models.RoleScopeGlobal,
+		Name:        name,
+		Description: requestData.Description,
+		Permissions: permissions,
+	}
+
+	if err := models.SaveRole(role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save role"})
+		return
+	}
+
+	// Новые права начинают действовать сразу на этом экземпляре бэкенда
+	policy.Invalidate()
+
+	// Роли общие для всех ботов, запись попадает в журнал бота администратора
+	var before interface{}
+	if previous != nil {
+		before = previous
+	}
+	recordPlatformAudit(c, models.AuditRoleSave, models.AuditTargetRole, name, before, role)
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole удаляет глобальную роль, которая не назначена ни одному пользователю
+// (только для администраторов из ADMIN_TELEGRAM_IDS)
+func DeleteRole(c *gin.Context) {
+	if !checkPlatformAdmin(c) {
+		return
+	}
+
+	name := c.Param("role")
+
+	if !models.IsEditableGlobalRole(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in roles cannot be deleted"})
+		return
+	}
+
+	count, err := models.CountUsersWithRole(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check role usage"})
+		return
+	}
+
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role is assigned to users", "users": count})
+		return
+	}
+
+	previous, err := models.GetRole(models.RoleScopeGlobal, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get role"})
+		return
+	}
+
+	deleted, err := models.DeleteRole(models.RoleScopeGlobal, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+
+	policy.Invalidate()
+
+	recordPlatformAudit(c, models.AuditRoleDelete, models.AuditTargetRole, name, previous, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
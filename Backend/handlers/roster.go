@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"prophecy/backend/models"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ограничения импортируемого списка игроков
+const (
+	maxRosterFileSize  = 1 << 20 // 1 МБ
+	maxRosterRows      = 1000
+	maxRosterTagLength = 100 // длина названия команды и клана
+)
+
+// telegramUsernamePattern допустимый username Telegram
+var telegramUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{5,32}$`)
+
+// rosterColumns номера колонок CSV файла со списком игроков
+type rosterColumns struct {
+	user, team, clan int
+}
+
+// parseRosterHeader определяет колонки по строке заголовка.
+// Если первая строка не похожа на заголовок, возвращается false и используется порядок по умолчанию.
+func parseRosterHeader(record []string) (rosterColumns, bool) {
+	columns := rosterColumns{user: -1, team: -1, clan: -1}
+	for i, name := range record {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "user", "username", "telegram_id", "telegram", "id":
+			columns.user = i
+		case "team":
+			columns.team = i
+		case "clan":
+			columns.clan = i
+		}
+	}
+
+	if columns.user < 0 {
+		return rosterColumns{user: 0, team: 1, clan: 2}, false
+	}
+	return columns, true
+}
+
+// rosterField возвращает значение колонки строки или пустую строку, если колонки нет
+func rosterField(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+// openRosterFile возвращает CSV файл из поля file multipart формы или из тела запроса.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func openRosterFile(c *gin.Context) (io.ReadCloser, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRosterFileSize)
+
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, true
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required in the 'file' field"})
+		return nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV file"})
+		return nil, false
+	}
+	return file, true
+}
+
+// ImportSessionRoster импортирует список игроков сессии из CSV файла.
+// Каждая строка содержит username (с @ или без) или Telegram ID и необязательные команду и клан.
+// Известные пользователи сразу добавляются в сессию, остальные ожидают первого входа в приложение.
+func ImportSessionRoster(c *gin.Context) {
+	user, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	if session.IsFinished() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session has already finished"})
+		return
+	}
+
+	file, ok := openRosterFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV file: " + err.Error()})
+		return
+	}
+
+	if len(records) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is empty"})
+		return
+	}
+
+	columns, hasHeader := parseRosterHeader(records[0])
+	firstRow := 1
+	if hasHeader {
+		records = records[1:]
+		firstRow = 2
+	}
+
+	if len(records) > maxRosterRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file must contain at most " + strconv.Itoa(maxRosterRows) + " rows"})
+		return
+	}
+
+	results := make([]models.RosterRowResult, 0, len(records))
+	summary := map[string]int{}
+	seen := map[string]bool{}
+
+	for i, record := range records {
+		result := models.RosterRowResult{
+			Row:   firstRow + i,
+			Value: rosterField(record, columns.user),
+			Team:  rosterField(record, columns.team),
+			Clan:  rosterField(record, columns.clan),
+		}
+
+		if err := importRosterRow(session, user.ID, &result, seen); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import roster"})
+			return
+		}
+
+		summary[result.Status]++
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"summary": summary,
+		"rows":    results,
+	})
+}
+
+// importRosterRow проверяет строку списка игроков и добавляет игрока в сессию или в список ожидающих.
+// Результат записывается в result, ошибка возвращается только при сбое базы данных.
+func importRosterRow(session *models.Session, addedBy int, result *models.RosterRowResult, seen map[string]bool) error {
+	invalid := func(message string) error {
+		result.Status = models.RosterRowInvalid
+		result.Error = message
+		return nil
+	}
+
+	if len(result.Team) > maxRosterTagLength || len(result.Clan) > maxRosterTagLength {
+		return invalid("Team and clan must be at most " + strconv.Itoa(maxRosterTagLength) + " characters long")
+	}
+
+	// Определяем, указан ли Telegram ID или username
+	value := result.Value
+	var key string
+	if telegramID, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if telegramID <= 0 {
+			return invalid("Telegram ID must be positive")
+		}
+		result.TelegramID = &telegramID
+		key = "id:" + value
+	} else {
+		username := strings.TrimPrefix(value, "@")
+		if username == "" {
+			return invalid("Username or Telegram ID is required")
+		}
+		if !telegramUsernamePattern.MatchString(username) {
+			return invalid("Invalid Telegram username")
+		}
+		result.Username = strings.ToLower(username)
+		key = "username:" + result.Username
+	}
+
+	if seen[key] {
+		result.Status = models.RosterRowDuplicate
+		return nil
+	}
+	seen[key] = true
+
+	var target *models.TelegramUser
+	var err error
+	if result.TelegramID != nil {
+		target, err = models.GetTelegramUserByTelegramID(session.TenantID, *result.TelegramID)
+	} else {
+		target, err = models.GetTelegramUserByUsername(session.TenantID, result.Username)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Пользователь еще не входил в приложение - ждем первого входа
+	if target == nil {
+		entry := &models.RosterEntry{
+			SessionID: session.ID,
+			Team:      result.Team,
+			Clan:      result.Clan,
+			AddedBy:   &addedBy,
+		}
+		if result.TelegramID != nil {
+			entry.TelegramID = result.TelegramID
+		} else {
+			entry.Username = &result.Username
+		}
+
+		if err := models.SaveRosterEntry(entry); err != nil {
+			return err
+		}
+		result.Status = models.RosterRowPending
+		return nil
+	}
+
+	result.UserID = &target.ID
+
+	if target.ID == session.ArchitectID {
+		return invalid("Architect cannot be added as player")
+	}
+
+	ban, err := models.GetActiveSessionBan(session.ID, target.ID)
+	if err != nil {
+		return err
+	}
+	if ban != nil {
+		result.Status = models.RosterRowBanned
+		return nil
+	}
+
+	added, err := models.AddPlayerToSessionWithTeam(target.ID, session.ID, result.Team, result.Clan)
+	if errors.Is(err, models.ErrSessionFull) {
+		result.Status = models.RosterRowSessionFull
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if added {
+		result.Status = models.RosterRowAdded
+	} else {
+		result.Status = models.RosterRowAlreadyMember
+	}
+	return nil
+}
+
+// GetSessionRoster получает игроков из импортированного списка, ожидающих первого входа
+func GetSessionRoster(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	entries, err := models.GetSessionRosterEntries(session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get roster"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// DeleteSessionRosterEntry удаляет ожидающего игрока из импортированного списка
+func DeleteSessionRosterEntry(c *gin.Context) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	entryID, err := strconv.Atoi(c.Param("entry_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid roster entry ID"})
+		return
+	}
+
+	deleted, err := models.DeleteRosterEntry(entryID, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete roster entry"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Roster entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Roster entry deleted successfully"})
+}
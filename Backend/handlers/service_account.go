@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"prophecy/backend/auth"
+	"prophecy/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getServiceAccountFromParam получает сервисный аккаунт текущего бота по параметру id из URL.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func getServiceAccountFromParam(c *gin.Context) (*models.ServiceAccount, bool) {
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return nil, false
+	}
+
+	account, err := models.GetServiceAccountByID(currentTenantID(c), accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get service account"})
+		return nil, false
+	}
+
+	if account == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return nil, false
+	}
+
+	return account, true
+}
+
+// CreateServiceAccount создает сервисный аккаунт (только для администраторов)
+func CreateServiceAccount(c *gin.Context) {
+	var requestData struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(requestData.Name)
+	if name == "" || len(name) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be between 1 and 255 characters"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	account := &models.ServiceAccount{
+		TenantID:  currentTenantID(c),
+		Name:      name,
+		CreatedBy: &userID,
+	}
+
+	if err := models.CreateServiceAccount(account); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account"})
+		return
+	}
+
+	recordAudit(c, models.AuditServiceAccountCreate, models.AuditTargetServiceAccount, account.ID, nil, account)
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// GetServiceAccounts получает сервисные аккаунты текущего бота (только для администраторов)
+func GetServiceAccounts(c *gin.Context) {
+	accounts, err := models.GetServiceAccounts(currentTenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get service accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// DisableServiceAccount отключает сервисный аккаунт и отзывает все его ключи (только для администраторов)
+func DisableServiceAccount(c *gin.Context) {
+	account, ok := getServiceAccountFromParam(c)
+	if !ok {
+		return
+	}
+
+	disabled, err := models.DisableServiceAccount(account.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable service account"})
+		return
+	}
+
+	if !disabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Service account is already disabled"})
+		return
+	}
+
+	recordAudit(c, models.AuditServiceAccountDisable, models.AuditTargetServiceAccount, account.ID, account, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service account disabled successfully"})
+}
+
+// CreateServiceAccountAPIKey выпускает новый API ключ сервисного аккаунта (только для администраторов).
+// Ключ возвращается только в этом ответе, в базе данных хранится лишь его хеш.
+func CreateServiceAccountAPIKey(c *gin.Context) {
+	account, ok := getServiceAccountFromParam(c)
+	if !ok {
+		return
+	}
+
+	if account.DisabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Service account is disabled"})
+		return
+	}
+
+	var requestData struct {
+		Scopes    []string   `json:"scopes" binding:"required"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(requestData.Scopes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one scope is required", "allowed_scopes": models.APIKeyScopes})
+		return
+	}
+
+	scopes := []string{}
+	for _, scope := range requestData.Scopes {
+		if !slices.Contains(models.APIKeyScopes, scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope: " + scope, "allowed_scopes": models.APIKeyScopes})
+			return
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if requestData.ExpiresAt != nil && !requestData.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiration time must be in the future"})
+		return
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	apiKey := &models.APIKey{
+		ServiceAccountID: account.ID,
+		Prefix:           prefix,
+		KeyHash:          hash,
+		Scopes:           scopes,
+		ExpiresAt:        requestData.ExpiresAt,
+		CreatedBy:        &userID,
+	}
+
+	if err := models.CreateAPIKey(apiKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	recordAudit(c, models.AuditAPIKeyCreate, models.AuditTargetAPIKey, apiKey.ID, nil, apiKey)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": apiKey,
+		"key":     key,
+	})
+}
+
+// GetServiceAccountAPIKeys получает ключи сервисного аккаунта без их значений (только для администраторов)
+func GetServiceAccountAPIKeys(c *gin.Context) {
+	account, ok := getServiceAccountFromParam(c)
+	if !ok {
+		return
+	}
+
+	keys, err := models.GetServiceAccountAPIKeys(account.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeServiceAccountAPIKey отзывает API ключ сервисного аккаунта (только для администраторов)
+func RevokeServiceAccountAPIKey(c *gin.Context) {
+	account, ok := getServiceAccountFromParam(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := strconv.Atoi(c.Param("key_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	revoked, err := models.RevokeAPIKey(account.ID, keyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	recordAudit(c, models.AuditAPIKeyRevoke, models.AuditTargetAPIKey, keyID, gin.H{"service_account_id": account.ID}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
@@ -3,10 +3,14 @@ package handlers
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"prophecy/backend/models"
+	"prophecy/backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,6 +24,81 @@ func generateReferralLink() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// getCurrentUser получает пользователя, выполняющего запрос.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func getCurrentUser(c *gin.Context) (*models.TelegramUser, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	user, err := models.GetTelegramUserByID(userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+		return nil, false
+	}
+
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// getSessionFromParam получает сессию по параметру id из URL.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func getSessionFromParam(c *gin.Context) (*models.Session, bool) {
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return nil, false
+	}
+
+	session, err := getTenantSession(c, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return nil, false
+	}
+
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return nil, false
+	}
+
+	return session, true
+}
+
+// getTenantSession получает сессию по ID. Сессии других ботов считаются несуществующими.
+func getTenantSession(c *gin.Context, sessionID int) (*models.Session, error) {
+	session, err := models.GetSessionByID(sessionID)
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	if session.TenantID != currentTenantID(c) {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// validateSchedule проверяет время начала и окончания сессии.
+// Возвращает текст ошибки или пустую строку, если расписание корректно.
+func validateSchedule(startsAt, endsAt *time.Time) string {
+	now := time.Now()
+	if startsAt != nil && !startsAt.After(now) {
+		return "Start time must be in the future"
+	}
+	if endsAt != nil && !endsAt.After(now) {
+		return "End time must be in the future"
+	}
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return "End time must be after start time"
+	}
+	return ""
+}
+
 // CreateSession создает новую сессию
 func CreateSession(c *gin.Context) {
 	// Получаем информацию о пользователе из контекста
@@ -38,8 +117,19 @@ func CreateSession(c *gin.Context) {
 
 	// Парсим данные из запроса
 	var requestData struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
+		Name        string                  `json:"name"`
+		Description string                  `json:"description"`
+		StartsAt    *time.Time              `json:"starts_at"`
+		EndsAt      *time.Time              `json:"ends_at"`
+		MaxPlayers  *int                    `json:"max_players"`
+		Settings    *models.SessionSettings `json:"settings"`
+		TemplateID  *int                    `json:"template_id"`
+		// Требуется ли одобрение заявок на вступление
+		RequiresApproval *bool `json:"requires_approval"`
+		// PIN-код или кодовая фраза для вступления
+		Pin string `json:"pin"`
+		// Показывать ли сессию в списке публичных сессий
+		IsPublic bool `json:"is_public"`
 	}
 
 	if err := c.ShouldBindJSON(&requestData); err != nil {
@@ -47,27 +137,181 @@ func CreateSession(c *gin.Context) {
 		return
 	}
 
+	session := &models.Session{ArchitectID: user.ID, IsPublic: requestData.IsPublic}
+
+	if requestData.Pin != "" {
+		pinHash, err := hashSessionPin(requestData.Pin)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		session.PinHash = pinHash
+	}
+
+	// Если указан шаблон, берем конфигурацию из него
+	if requestData.TemplateID != nil {
+		template, err := models.GetSessionTemplateByID(*requestData.TemplateID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get template"})
+			return
+		}
+
+		if template == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+
+		// Чужие шаблоны считаем несуществующими
+		canUse, ok := checkPermission(c, user, policy.TemplateUse, template)
+		if !ok {
+			return
+		}
+		if !canUse {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+
+		session.Name = template.Name
+		session.Description = template.Description
+		session.MaxPlayers = template.MaxPlayers
+		session.Settings = template.Settings
+	}
+
+	// Поля из запроса имеют приоритет над шаблоном
+	if requestData.Name != "" {
+		session.Name = requestData.Name
+	}
+	if requestData.Description != "" {
+		session.Description = requestData.Description
+	}
+	if requestData.MaxPlayers != nil {
+		session.MaxPlayers = requestData.MaxPlayers
+	}
+	if requestData.Settings != nil {
+		session.Settings = *requestData.Settings
+	}
+	if requestData.RequiresApproval != nil {
+		session.RequiresApproval = *requestData.RequiresApproval
+	}
+	session.StartsAt = requestData.StartsAt
+	session.EndsAt = requestData.EndsAt
+
+	if session.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session name is required"})
+		return
+	}
+
+	if !createNewSession(c, session) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// createNewSession проверяет конфигурацию новой сессии, генерирует реферальную ссылку и сохраняет сессию.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func createNewSession(c *gin.Context, session *models.Session) bool {
+	if msg := validateSchedule(session.StartsAt, session.EndsAt); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return false
+	}
+
+	if msg := validateCapacity(session.MaxPlayers); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return false
+	}
+
+	if err := session.Settings.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
 	// Генерируем реферальную ссылку
 	referralLink, err := generateReferralLink()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate referral link"})
-		return
+		return false
 	}
+	session.ReferralLink = referralLink
 
-	// Создаем новую сессию
-	session := &models.Session{
-		Name:         requestData.Name,
-		Description:  requestData.Description,
-		ArchitectID:  user.ID,
-		ReferralLink: referralLink,
+	// Сессия с временем начала ждет открытия лобби планировщиком
+	session.Status = models.SessionStatusLobby
+	if session.StartsAt != nil {
+		session.Status = models.SessionStatusScheduled
 	}
 
+	session.TenantID = currentTenantID(c)
 	if err := models.CreateSession(session); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
-		return
+		return false
 	}
 
-	c.JSON(http.StatusCreated, session)
+	return true
+}
+
+// validateCapacity проверяет максимальное количество игроков.
+// Возвращает текст ошибки или пустую строку, если значение корректно.
+func validateCapacity(maxPlayers *int) string {
+	if maxPlayers != nil && *maxPlayers <= 0 {
+		return "max_players must be positive"
+	}
+	return ""
+}
+
+// parseSessionFilter разбирает параметры запроса для списка сессий:
+// limit, offset, status (через запятую), architect_id, created_from, created_to, q, sort и order.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func parseSessionFilter(c *gin.Context) (models.SessionFilter, bool) {
+	limit, offset := parsePagination(c, 20)
+	filter := models.SessionFilter{
+		Search:   strings.TrimSpace(c.Query("q")),
+		SortBy:   c.DefaultQuery("sort", "created_at"),
+		SortDesc: c.DefaultQuery("order", "desc") != "asc",
+		Limit:    limit,
+		Offset:   offset,
+	}
+
+	if status := c.Query("status"); status != "" {
+		filter.Statuses = strings.Split(status, ",")
+	}
+	filter.Archived = c.Query("archived") == "true"
+	filter.Deleted = c.Query("deleted") == "true"
+
+	if architectParam := c.Query("architect_id"); architectParam != "" {
+		architectID, err := strconv.Atoi(architectParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid architect ID"})
+			return filter, false
+		}
+		filter.ArchitectID = architectID
+	}
+
+	for param, target := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + ". Use RFC 3339 or YYYY-MM-DD"})
+			return filter, false
+		}
+		*target = &parsed
+	}
+
+	return filter, true
+}
+
+// parseTimeParam разбирает время в формате RFC 3339 или дату в формате YYYY-MM-DD
+func parseTimeParam(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse("2006-01-02", value)
 }
 
 // GetSessions получает список сессий
@@ -86,25 +330,36 @@ func GetSessions(c *gin.Context) {
 		return
 	}
 
-	var sessions interface{}
-	var sessionsErr error
+	// Получаем параметры фильтрации, сортировки и пагинации
+	filter, ok := parseSessionFilter(c)
+	if !ok {
+		return
+	}
 
-	// Если пользователь админ, получаем все сессии
-	if user.IsAdmin {
-		sessions, sessionsErr = models.GetAllSessions()
-	} else if user.Role == "Архитектор" {
-		// Если пользователь архитектор, получаем только его сессии
-		sessions, sessionsErr = models.GetSessionsByArchitectID(user.ID)
-	} else {
-		// Для обычных пользователей возвращаем пустой список
-		sessions = []models.Session{}
+	// Удаленные сессии видны только тем, кто может их восстановить
+	if filter.Deleted && !authorize(c, user, policy.SessionRestore, nil) {
+		return
+	}
+
+	// Пользователи с правом просмотра всех сессий (админы) получают все сессии бота,
+	// остальные - сессии, которые они создали или помогают вести
+	canViewAll, ok := checkPermission(c, user, policy.SessionView, nil)
+	if !ok {
+		return
+	}
+
+	filter.TenantID = currentTenantID(c)
+	if !canViewAll {
+		filter.ManagedBy = user.ID
 	}
 
-	if sessionsErr != nil {
+	sessions, total, err := models.ListSessions(filter)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sessions"})
 		return
 	}
 
+	c.Header("X-Total-Count", strconv.Itoa(total))
 	c.JSON(http.StatusOK, sessions)
 }
 
@@ -132,7 +387,7 @@ func GetSession(c *gin.Context) {
 	}
 
 	// Получаем сессию из базы данных
-	session, err := models.GetSessionByID(sessionID)
+	session, err := getTenantSession(c, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
@@ -144,9 +399,8 @@ func GetSession(c *gin.Context) {
 	}
 
 	// Проверяем права доступа к сессии
-	// Админы и архитектор, создавший сессию, могут получить к ней доступ
-	if !user.IsAdmin && user.Role != "Архитектор" && session.ArchitectID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	// Админы, архитектор, создавший сессию, и участники сессии могут получить к ней доступ
+	if !authorize(c, user, policy.SessionView, session) {
 		return
 	}
 
@@ -177,7 +431,7 @@ func UpdateSession(c *gin.Context) {
 	}
 
 	// Получаем сессию из базы данных
-	session, err := models.GetSessionByID(sessionID)
+	session, err := getTenantSession(c, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
@@ -188,16 +442,23 @@ func UpdateSession(c *gin.Context) {
 		return
 	}
 
-	// Только архитектор, создавший сессию, или админ может обновить её
-	if !user.IsAdmin && session.ArchitectID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	// Только архитектор, создавший сессию, со-архитектор или админ может обновить её
+	if !authorize(c, user, policy.SessionManage, session) {
 		return
 	}
 
 	// Парсим данные из запроса
 	var requestData struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
+		Name        string                  `json:"name"`
+		Description string                  `json:"description"`
+		StartsAt    *time.Time              `json:"starts_at"`
+		EndsAt      *time.Time              `json:"ends_at"`
+		MaxPlayers  *int                    `json:"max_players"`
+		Settings    *models.SessionSettings `json:"settings"`
+		// Требуется ли одобрение заявок на вступление
+		RequiresApproval *bool `json:"requires_approval"`
+		// Показывать ли сессию в списке публичных сессий
+		IsPublic *bool `json:"is_public"`
 	}
 
 	if err := c.ShouldBindJSON(&requestData); err != nil {
@@ -205,20 +466,89 @@ func UpdateSession(c *gin.Context) {
 		return
 	}
 
-	// Обновляем данные сессии
+	// Изменяются только переданные поля, остальные (в том числе статус) остаются как в базе данных
+	update := models.SessionUpdate{
+		StartsAt:         requestData.StartsAt,
+		EndsAt:           requestData.EndsAt,
+		Settings:         requestData.Settings,
+		RequiresApproval: requestData.RequiresApproval,
+		IsPublic:         requestData.IsPublic,
+		ExpectedStatus:   session.Status,
+	}
 	if requestData.Name != "" {
-		session.Name = requestData.Name
+		update.Name = &requestData.Name
 	}
 	if requestData.Description != "" {
-		session.Description = requestData.Description
+		update.Description = &requestData.Description
+	}
+
+	if requestData.MaxPlayers != nil {
+		if msg := validateCapacity(requestData.MaxPlayers); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		update.MaxPlayers = requestData.MaxPlayers
+	}
+	if requestData.Settings != nil {
+		if err := requestData.Settings.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Начало можно менять только до начала игры, окончание - до ее завершения
+	if requestData.StartsAt != nil || requestData.EndsAt != nil {
+		if requestData.StartsAt != nil && session.Status != models.SessionStatusScheduled && session.Status != models.SessionStatusLobby {
+			c.JSON(http.StatusConflict, gin.H{"error": "Start time cannot be changed after the game has started"})
+			return
+		}
+		if requestData.EndsAt != nil && session.IsFinished() {
+			c.JSON(http.StatusConflict, gin.H{"error": "End time cannot be changed after the game has finished"})
+			return
+		}
+
+		startsAt, endsAt := session.StartsAt, session.EndsAt
+		if requestData.StartsAt != nil {
+			startsAt = requestData.StartsAt
+		}
+		if requestData.EndsAt != nil {
+			endsAt = requestData.EndsAt
+		}
+
+		// В будущем должны быть только изменяемые значения: уже наступившее начало
+		// идущей сессии не мешает продлить ее
+		if msg := validateSchedule(requestData.StartsAt, requestData.EndsAt); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "End time must be after start time"})
+			return
+		}
 	}
 
-	if err := models.UpdateSession(session); err != nil {
+	// При переносе начала сессия снова ждет планировщика, а напоминание отправится заново.
+	// Если планировщик успел сменить статус сессии, расписание не меняется.
+	updated, err := models.UpdateSession(session.ID, update)
+	if errors.Is(err, models.ErrSessionStatusChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session status has changed, reload the session and try again"})
+		return
+	}
+	if errors.Is(err, models.ErrCapacityBelowPlayers) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Max players cannot be less than the current number of players"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
 		return
 	}
 
-	c.JSON(http.StatusOK, session)
+	if updated == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
 }
 
 // DeleteSession удаляет сессию
@@ -245,7 +575,7 @@ func DeleteSession(c *gin.Context) {
 	}
 
 	// Получаем сессию из базы данных
-	session, err := models.GetSessionByID(sessionID)
+	session, err := getTenantSession(c, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
@@ -256,18 +586,19 @@ func DeleteSession(c *gin.Context) {
 		return
 	}
 
-	// Только архитектор, создавший сессию, или админ может удалить её
-	if !user.IsAdmin && session.ArchitectID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	// Только архитектор, создавший сессию, со-архитектор или админ может удалить её
+	if !authorize(c, user, policy.SessionManage, session) {
 		return
 	}
 
-	// Удаляем сессию
-	if err := models.DeleteSession(sessionID); err != nil {
+	// Помечаем сессию удаленной, админ может её восстановить до окончания срока хранения
+	if err := models.DeleteSession(sessionID, user.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete session"})
 		return
 	}
 
+	recordAudit(c, models.AuditSessionDelete, models.AuditTargetSession, session.ID, session, nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Session deleted successfully"})
 }
 
@@ -295,7 +626,7 @@ func AddPlayerToSession(c *gin.Context) {
 	}
 
 	// Получаем сессию из базы данных
-	session, err := models.GetSessionByID(sessionID)
+	session, err := getTenantSession(c, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
@@ -307,10 +638,15 @@ func AddPlayerToSession(c *gin.Context) {
 	}
 
 	// Проверяем права доступа
-	// Игроки могут присоединяться к сессиям, админы и архитекторы могут добавлять игроков
+	// Игроки могут присоединяться к сессиям, управляющие сессией могут добавлять игроков
+	canManage, ok := checkPermission(c, user, policy.SessionManage, session)
+	if !ok {
+		return
+	}
+
 	var playerID int
-	if user.IsAdmin || user.Role == "Архитектор" {
-		// Админы и архитекторы могут добавлять любого игрока
+	if canManage {
+		// Админы, создатель сессии и со-архитекторы могут добавлять любого игрока
 		playerIDParam := c.Query("player_id")
 		if playerIDParam == "" {
 			// Если не указан игрок, добавляем текущего пользователя
@@ -321,10 +657,35 @@ func AddPlayerToSession(c *gin.Context) {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
 				return
 			}
+
+			// Добавить можно только пользователя того же бота
+			player, err := getTenantUser(c, playerID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+				return
+			}
+			if player == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				return
+			}
 		}
 	} else {
-		// Обычные пользователи могут добавить только себя
-		playerID = user.ID
+		// Обычные пользователи могут добавить только себя и только в публичную сессию,
+		// в остальные сессии вступают по приглашению
+		if !session.IsPublic {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Use an invite link to join this session"})
+			return
+		}
+
+		// Публичная сессия принимает PIN-код и заявки на вступление так же, как POST /sessions/:id/join
+		joinPublicSession(c, user.ID, session)
+		return
+	}
+
+	// К завершенной игре нельзя присоединиться
+	if session.IsFinished() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session has already finished"})
+		return
 	}
 
 	// Проверяем, не является ли пользователь архитектором этой сессии
@@ -333,8 +694,22 @@ func AddPlayerToSession(c *gin.Context) {
 		return
 	}
 
+	// Забаненного пользователя нельзя добавить в сессию
+	if !checkNotBanned(c, sessionID, playerID) {
+		return
+	}
+
+	// В сессию заблокированного архитектора нельзя добавить игроков, как и вступить в нее
+	if !checkArchitectNotSuspended(c, session) {
+		return
+	}
+
 	// Добавляем игрока к сессии
 	if err := models.AddPlayerToSession(playerID, sessionID); err != nil {
+		if errors.Is(err, models.ErrSessionFull) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Session is full"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add player to session"})
 		return
 	}
@@ -342,7 +717,7 @@ func AddPlayerToSession(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Player added to session successfully"})
 }
 
-// RemovePlayerFromSession удаляет игрока из сессии
+// RemovePlayerFromSession удаляет игрока из сессии (кик без запрета на повторный вход)
 func RemovePlayerFromSession(c *gin.Context) {
 	// Получаем ID сессии из параметров URL
 	sessionID, err := strconv.Atoi(c.Param("id"))
@@ -366,7 +741,7 @@ func RemovePlayerFromSession(c *gin.Context) {
 	}
 
 	// Получаем сессию из базы данных
-	session, err := models.GetSessionByID(sessionID)
+	session, err := getTenantSession(c, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
@@ -378,9 +753,14 @@ func RemovePlayerFromSession(c *gin.Context) {
 	}
 
 	// Определяем, какого игрока нужно удалить
+	canModerate, ok := checkPermission(c, user, policy.SessionModerate, session)
+	if !ok {
+		return
+	}
+
 	var playerID int
-	if user.IsAdmin || (user.Role == "Архитектор" && session.ArchitectID == user.ID) {
-		// Админы и архитекторы своей сессии могут удалить любого игрока
+	if canModerate {
+		// Админы, создатель сессии, со-архитекторы и модераторы могут удалить игрока
 		playerIDParam := c.Query("player_id")
 		if playerIDParam == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Player ID is required"})
@@ -397,46 +777,50 @@ func RemovePlayerFromSession(c *gin.Context) {
 		playerID = user.ID
 	}
 
+	// Участников с особыми ролями может исключить только создатель сессии или админ
+	if playerID != user.ID {
+		canGrantRoles, ok := checkPermission(c, user, policy.SessionGrantRoles, session)
+		if !ok {
+			return
+		}
+
+		if !canGrantRoles {
+			role, err := models.GetSessionMemberRole(sessionID, playerID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session membership"})
+				return
+			}
+
+			if role != "" && role != models.SessionRolePlayer {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+				return
+			}
+		}
+	}
+
 	// Удаляем игрока из сессии
 	if err := models.RemovePlayerFromSession(playerID, sessionID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove player from session"})
 		return
 	}
 
+	// Выход из сессии по своей воле не является привилегированным действием
+	if playerID != user.ID {
+		recordAudit(c, models.AuditSessionRemovePlayer, models.AuditTargetSession, sessionID, gin.H{"player_id": playerID}, nil)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Player removed from session successfully"})
 }
 
 // GetSessionPlayers получает всех игроков в сессии
 func GetSessionPlayers(c *gin.Context) {
-	// Получаем ID сессии из параметров URL
-	sessionID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
-		return
-	}
-
-	// Получаем информацию о пользователе из контекста
-	_, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-
-	// Получаем сессию из базы данных
-	session, err := models.GetSessionByID(sessionID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+	// Игроков видят те же пользователи, что и саму сессию
+	_, session, ok := loadSessionForAction(c, policy.SessionView)
+	if !ok {
 		return
 	}
 
-	if session == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
-		return
-	}
-
-	// Проверяем права доступа
-	// Все пользователи могут видеть игроков в сессии
-	players, err := models.GetSessionPlayers(sessionID)
+	players, err := models.GetSessionPlayers(session.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session players"})
 		return
@@ -461,97 +845,319 @@ func GetPlayerSessions(c *gin.Context) {
 		return
 	}
 
-	// Определяем, для какого игрока получаем сессии
-	var playerID int
-	if user.IsAdmin {
-		// Админы могут получить сессии любого игрока
-		playerIDParam := c.Query("player_id")
-		if playerIDParam == "" {
-			// Если не указан игрок, получаем сессии текущего пользователя
-			playerID = user.ID
-		} else {
-			playerID, err = strconv.Atoi(playerIDParam)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
-				return
-			}
+	// Определяем, для какого игрока получаем сессии.
+	// Пользователи с правом просмотра пользователей (админы) могут получить сессии любого игрока,
+	// остальные получают только свои сессии
+	canViewUsers, ok := checkPermission(c, user, policy.UsersView, nil)
+	if !ok {
+		return
+	}
+
+	playerID := user.ID
+	if playerIDParam := c.Query("player_id"); playerIDParam != "" && canViewUsers {
+		playerID, err = strconv.Atoi(playerIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+			return
+		}
+
+		// Получить можно только сессии пользователя того же бота
+		player, err := getTenantUser(c, playerID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+			return
+		}
+		if player == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
 		}
-	} else {
-		// Обычные пользователи могут получить только свои сессии
-		playerID = user.ID
 	}
 
+	// Получаем параметры фильтрации, сортировки и пагинации
+	filter, ok := parseSessionFilter(c)
+	if !ok {
+		return
+	}
+
+	// Удаленные сессии видны только тем, кто может их восстановить
+	if filter.Deleted && !authorize(c, user, policy.SessionRestore, nil) {
+		return
+	}
+	filter.MemberID = playerID
+
 	// Получаем сессии игрока
-	sessions, err := models.GetPlayerSessions(playerID)
+	sessions, total, err := models.ListSessions(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get player sessions"})
 		return
 	}
 
+	c.Header("X-Total-Count", strconv.Itoa(total))
 	c.JSON(http.StatusOK, sessions)
 }
 
-// JoinSessionByReferral присоединяет пользователя к сессии по реферальной ссылке
+// JoinSessionByReferral присоединяет пользователя к сессии по приглашению.
+// GET запрос возвращает информацию о сессии, не добавляя пользователя.
 func JoinSessionByReferral(c *gin.Context) {
-	// Проверяем метод запроса
-	if c.Request.Method == "POST" {
-		// Получаем информацию о пользователе из контекста
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-			return
-		}
+	// Получаем приглашение по коду из ссылки
+	invite, err := models.GetSessionInviteByCode(c.Param("referral_link"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invite"})
+		return
+	}
 
-		// Получаем сессию из базы данных по реферальной ссылке
-		session, err := models.GetSessionByReferralLink(c.Param("referral_link"))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
-			return
-		}
+	if invite == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	// Проверяем, что приглашение не отозвано, не истекло и не исчерпано
+	if err := invite.CheckUsable(time.Now()); err != nil {
+		respondInviteError(c, err)
+		return
+	}
+
+	// Получаем сессию, к которой относится приглашение
+	session, err := getTenantSession(c, invite.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if !checkArchitectNotSuspended(c, session) {
+		return
+	}
 
-		if session == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+	// Для GET запроса возвращаем информацию о сессии.
+	// О сессии с PIN-кодом сообщаем только то, что PIN-код требуется.
+	if c.Request.Method != http.MethodPost {
+		if session.PinRequired {
+			c.JSON(http.StatusOK, gin.H{"pin_required": true})
 			return
 		}
+		c.JSON(http.StatusOK, session)
+		return
+	}
 
-		// Проверяем, не является ли пользователь архитектором этой сессии
-		if session.ArchitectID == userID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Architect cannot be added as player"})
-			return
+	var requestData struct {
+		Pin string `json:"pin"`
+	}
+
+	if !bindOptionalJSON(c, &requestData) {
+		return
+	}
+
+	// Получаем информацию о пользователе из контекста
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	joinSession(c, userID.(int), session, invite, requestData.Pin)
+}
+
+// joinSession добавляет пользователя в сессию по приглашению (nil для публичной сессии) или,
+// если сессия требует одобрения, создает заявку на вступление. Ответ отправляется клиенту.
+func joinSession(c *gin.Context, userID int, session *models.Session, invite *models.SessionInvite, pin string) {
+	// К завершенной игре нельзя присоединиться
+	if session.IsFinished() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session has already finished"})
+		return
+	}
+
+	// Проверяем, не является ли пользователь архитектором этой сессии
+	if session.ArchitectID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Architect cannot be added as player"})
+		return
+	}
+
+	// Забаненный пользователь не может вернуться в сессию по приглашению
+	if !checkNotBanned(c, session.ID, userID) {
+		return
+	}
+
+	// Проверяем, не участвует ли пользователь уже в сессии
+	isPlayer, err := models.IsPlayerInSession(userID, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check player status"})
+		return
+	}
+
+	if isPlayer {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is already in session"})
+		return
+	}
+
+	// Проверяем PIN-код сессии, если он установлен
+	if !checkSessionPin(c, session, userID, pin) {
+		return
+	}
+
+	// В сессию с одобрением вместо вступления создается заявка
+	if session.RequiresApproval {
+		var inviteID *int
+		if invite != nil {
+			inviteID = &invite.ID
 		}
 
-		// Проверяем, не участвует ли пользователь уже в сессии
-		isPlayer, err := models.IsPlayerInSession(userID.(int), session.ID)
+		request, err := models.CreateJoinRequest(session.ID, userID, inviteID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check player status"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create join request"})
 			return
 		}
 
-		if isPlayer {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is already in session"})
-			return
-		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Join request submitted and is awaiting approval",
+			"request": request,
+		})
+		return
+	}
 
-		// Добавляем игрока к сессии
-		if err := models.AddPlayerToSession(userID.(int), session.ID); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add player to session"})
+	// Добавляем игрока к сессии, учитывая использование приглашения, если оно есть
+	if invite != nil {
+		err = models.JoinSessionWithInvite(userID, invite)
+	} else {
+		err = models.AddPlayerToSession(userID, session.ID)
+	}
+	if err != nil {
+		if isInviteError(err) {
+			respondInviteError(c, err)
 			return
 		}
-
-		c.JSON(http.StatusOK, gin.H{"message": "Successfully joined session"})
-	} else {
-		// Для GET запроса возвращаем информацию о сессии
-		session, err := models.GetSessionByReferralLink(c.Param("referral_link"))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		if errors.Is(err, models.ErrSessionFull) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Session is full"})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add player to session"})
+		return
+	}
 
-		if session == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
-			return
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully joined session"})
+}
+
+// ArchiveSession переносит завершенную сессию в архив
+func ArchiveSession(c *gin.Context) {
+	setSessionArchived(c, true)
+}
+
+// UnarchiveSession возвращает сессию из архива
+func UnarchiveSession(c *gin.Context) {
+	setSessionArchived(c, false)
+}
+
+// setSessionArchived переносит сессию в архив или возвращает её из архива
+func setSessionArchived(c *gin.Context, archived bool) {
+	_, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	changed, err := models.SetSessionArchived(session.ID, archived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
+		return
+	}
+
+	if !changed {
+		if archived {
+			c.JSON(http.StatusConflict, gin.H{"error": "Only finished sessions can be archived"})
+		} else {
+			c.JSON(http.StatusConflict, gin.H{"error": "Session is not archived"})
 		}
+		return
+	}
 
-		c.JSON(http.StatusOK, session)
+	if archived {
+		c.JSON(http.StatusOK, gin.H{"message": "Session archived successfully"})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": "Session unarchived successfully"})
+	}
+}
+
+// RestoreSession восстанавливает удаленную сессию (только для админов)
+func RestoreSession(c *gin.Context) {
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	restored, err := models.RestoreSession(currentTenantID(c), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore session"})
+		return
+	}
+
+	if !restored {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted session not found"})
+		return
+	}
+
+	session, err := getTenantSession(c, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+
+	recordAudit(c, models.AuditSessionRestore, models.AuditTargetSession, sessionID, nil, session)
+
+	c.JSON(http.StatusOK, session)
+}
+
+// GetPublicSessions получает открытые публичные сессии, в которые можно вступить без приглашения
+func GetPublicSessions(c *gin.Context) {
+	limit, offset := parsePagination(c, 20)
+
+	sessions, total, err := models.ListPublicSessions(currentTenantID(c), strings.TrimSpace(c.Query("q")), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sessions"})
+		return
 	}
+
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.JSON(http.StatusOK, sessions)
+}
+
+// JoinPublicSession присоединяет текущего пользователя к публичной сессии без приглашения
+func JoinPublicSession(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	session, ok := getSessionFromParam(c)
+	if !ok {
+		return
+	}
+
+	// Непубличную сессию без приглашения считаем несуществующей
+	if !session.IsPublic {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	joinPublicSession(c, user.ID, session)
+}
+
+// joinPublicSession присоединяет пользователя к публичной сессии с PIN-кодом из тела запроса
+func joinPublicSession(c *gin.Context, userID int, session *models.Session) {
+	if !checkArchitectNotSuspended(c, session) {
+		return
+	}
+
+	var requestData struct {
+		Pin string `json:"pin"`
+	}
+
+	if !bindOptionalJSON(c, &requestData) {
+		return
+	}
+
+	joinSession(c, userID, session, nil, requestData.Pin)
 }
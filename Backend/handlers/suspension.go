@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"prophecy/backend/auth"
+	"prophecy/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ensureNotSuspended проверяет, что пользователь не заблокирован во всем приложении.
+// Если пользователь заблокирован или произошла ошибка, ответ уже отправлен клиенту и возвращается false.
+func ensureNotSuspended(c *gin.Context, userID int) bool {
+	suspension, err := models.GetActiveUserSuspension(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check user suspension"})
+		return false
+	}
+
+	if suspension != nil {
+		c.JSON(http.StatusForbidden, auth.SuspendedResponse(suspension))
+		return false
+	}
+
+	return true
+}
+
+// SuspendUser блокирует пользователя на срок или бессрочно (требуется право users.suspend).
+// Заблокированный пользователь не может войти, его токены и API ключи не принимаются,
+// а сам он не отображается в списках игроков и участников сессий.
+func SuspendUser(c *gin.Context) {
+	target, ok := getTargetUserFromParam(c)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		Reason        string     `json:"reason"`
+		ExpiresAt     *time.Time `json:"expires_at"`
+		DurationHours int        `json:"duration_hours"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	if target.ID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot suspend yourself"})
+		return
+	}
+
+	// Администратора сначала нужно лишить прав, иначе можно заблокировать всех администраторов бота
+	if target.IsAdmin {
+		c.JSON(http.StatusConflict, gin.H{"error": "Admins cannot be suspended, revoke admin rights first"})
+		return
+	}
+
+	// Блокировка без срока действует бессрочно
+	expiresAt := requestData.ExpiresAt
+	if expiresAt == nil && requestData.DurationHours > 0 {
+		t := time.Now().Add(time.Duration(requestData.DurationHours) * time.Hour)
+		expiresAt = &t
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiration time must be in the future"})
+		return
+	}
+
+	previous, err := models.GetActiveUserSuspension(target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check user suspension"})
+		return
+	}
+
+	suspension := &models.UserSuspension{
+		UserID:      target.ID,
+		Reason:      requestData.Reason,
+		SuspendedBy: &userID,
+		ExpiresAt:   expiresAt,
+		UserName:    target.GeneratedName,
+	}
+
+	if err := models.CreateUserSuspension(suspension); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+		return
+	}
+
+	// На этом экземпляре блокировка начинает действовать сразу, на остальных - после истечения кеша прав
+	auth.ForgetUserPermissions(target.ID)
+
+	// Новая блокировка заменяет действующую, которая попадает в журнал как предыдущее состояние
+	var before interface{}
+	if previous != nil {
+		before = previous
+	}
+	recordAudit(c, models.AuditUserSuspend, models.AuditTargetUser, target.ID, before, suspension)
+
+	c.JSON(http.StatusCreated, suspension)
+}
+
+// LiftUserSuspension снимает блокировку пользователя (требуется право users.suspend)
+func LiftUserSuspension(c *gin.Context) {
+	target, ok := getTargetUserFromParam(c)
+	if !ok {
+		return
+	}
+
+	suspension, err := models.GetActiveUserSuspension(target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check user suspension"})
+		return
+	}
+
+	lifted, err := models.LiftUserSuspension(target.ID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lift suspension"})
+		return
+	}
+
+	if !lifted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suspension not found"})
+		return
+	}
+
+	auth.ForgetUserPermissions(target.ID)
+
+	var before interface{}
+	if suspension != nil {
+		before = suspension
+	}
+	recordAudit(c, models.AuditUserLiftSuspension, models.AuditTargetUser, target.ID, before, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Suspension lifted successfully"})
+}
+
+// checkArchitectNotSuspended проверяет, что создатель сессии не заблокирован.
+// Сессии заблокированного пользователя не выводятся в списках и недоступны для вступления, пока блокировка действует.
+// Если создатель заблокирован или произошла ошибка, ответ уже отправлен клиенту и возвращается false.
+func checkArchitectNotSuspended(c *gin.Context, session *models.Session) bool {
+	suspension, err := models.GetActiveUserSuspension(session.ArchitectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check user suspension"})
+		return false
+	}
+
+	if suspension != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return false
+	}
+
+	return true
+}
+
+// GetUserSuspensions получает историю блокировок пользователя, новые первыми (требуется право users.suspend)
+func GetUserSuspensions(c *gin.Context) {
+	target, ok := getTargetUserFromParam(c)
+	if !ok {
+		return
+	}
+
+	suspensions, err := models.GetUserSuspensions(target.TenantID, target.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user suspensions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suspensions)
+}
+
+// GetSuspensions получает блокировки пользователей текущего бота (по умолчанию только действующие, all=true - все)
+func GetSuspensions(c *gin.Context) {
+	activeOnly := c.Query("all") != "true"
+
+	suspensions, err := models.GetUserSuspensions(currentTenantID(c), 0, activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user suspensions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suspensions)
+}
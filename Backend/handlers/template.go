@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"prophecy/backend/models"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getTemplateFromParam получает шаблон по параметру id из URL и проверяет доступ к нему.
+// При ошибке ответ уже отправлен клиенту и возвращается false.
+func getTemplateFromParam(c *gin.Context, user *models.TelegramUser) (*models.SessionTemplate, bool) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return nil, false
+	}
+
+	template, err := models.GetSessionTemplateByID(templateID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get template"})
+		return nil, false
+	}
+
+	if template == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil, false
+	}
+
+	// Чужие шаблоны считаем несуществующими
+	canUse, ok := checkPermission(c, user, policy.TemplateUse, template)
+	if !ok {
+		return nil, false
+	}
+	if !canUse {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil, false
+	}
+
+	return template, true
+}
+
+// CreateSessionTemplate создает шаблон сессии
+func CreateSessionTemplate(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		Name        string                 `json:"name" binding:"required"`
+		Description string                 `json:"description"`
+		MaxPlayers  *int                   `json:"max_players"`
+		Settings    models.SessionSettings `json:"settings"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if msg := validateCapacity(requestData.MaxPlayers); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if err := requestData.Settings.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template := &models.SessionTemplate{
+		OwnerID:     user.ID,
+		Name:        requestData.Name,
+		Description: requestData.Description,
+		MaxPlayers:  requestData.MaxPlayers,
+		Settings:    requestData.Settings,
+	}
+
+	if err := models.CreateSessionTemplate(template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetSessionTemplates получает список шаблонов (админы получают все шаблоны, архитекторы - только свои)
+func GetSessionTemplates(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	// Пользователи с правом на все шаблоны бота (админы) получают все шаблоны
+	canUseAll, ok := checkPermission(c, user, policy.TemplateUse, nil)
+	if !ok {
+		return
+	}
+
+	ownerID := user.ID
+	if canUseAll {
+		ownerID = 0
+	}
+
+	templates, err := models.GetSessionTemplates(user.TenantID, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetSessionTemplate получает шаблон по ID
+func GetSessionTemplate(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	template, ok := getTemplateFromParam(c, user)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateSessionTemplate обновляет шаблон сессии
+func UpdateSessionTemplate(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	template, ok := getTemplateFromParam(c, user)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		Name        string                  `json:"name"`
+		Description string                  `json:"description"`
+		MaxPlayers  *int                    `json:"max_players"`
+		Settings    *models.SessionSettings `json:"settings"`
+	}
+
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if requestData.Name != "" {
+		template.Name = requestData.Name
+	}
+	if requestData.Description != "" {
+		template.Description = requestData.Description
+	}
+	if requestData.MaxPlayers != nil {
+		if msg := validateCapacity(requestData.MaxPlayers); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		template.MaxPlayers = requestData.MaxPlayers
+	}
+	if requestData.Settings != nil {
+		if err := requestData.Settings.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		template.Settings = *requestData.Settings
+	}
+
+	if err := models.UpdateSessionTemplate(template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteSessionTemplate удаляет шаблон сессии
+func DeleteSessionTemplate(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	template, ok := getTemplateFromParam(c, user)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteSessionTemplate(template.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// SaveSessionAsTemplate сохраняет конфигурацию сессии как шаблон
+func SaveSessionAsTemplate(c *gin.Context) {
+	user, session, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		Name string `json:"name"`
+	}
+
+	if !bindOptionalJSON(c, &requestData) {
+		return
+	}
+
+	template := &models.SessionTemplate{
+		OwnerID:     user.ID,
+		Name:        session.Name,
+		Description: session.Description,
+		MaxPlayers:  session.MaxPlayers,
+		Settings:    session.Settings,
+	}
+	if requestData.Name != "" {
+		template.Name = requestData.Name
+	}
+
+	if err := models.CreateSessionTemplate(template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// CloneSession создает копию сессии без игроков
+func CloneSession(c *gin.Context) {
+	user, source, ok := loadSessionForAction(c, policy.SessionManage)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		Name     string     `json:"name"`
+		StartsAt *time.Time `json:"starts_at"`
+		EndsAt   *time.Time `json:"ends_at"`
+	}
+
+	if !bindOptionalJSON(c, &requestData) {
+		return
+	}
+
+	// Копируем конфигурацию, но не игроков, приглашения и расписание исходной сессии
+	session := &models.Session{
+		Name:        source.Name,
+		Description: source.Description,
+		ArchitectID: user.ID,
+		MaxPlayers:  source.MaxPlayers,
+		Settings:    source.Settings,
+		StartsAt:    requestData.StartsAt,
+		EndsAt:      requestData.EndsAt,
+	}
+	if requestData.Name != "" {
+		session.Name = requestData.Name
+	}
+
+	if !createNewSession(c, session) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
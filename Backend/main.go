@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 
+	"prophecy/backend/auth"
 	"prophecy/backend/config"
 	"prophecy/backend/database"
+	"prophecy/backend/handlers"
 	"prophecy/backend/routes"
+	"prophecy/backend/scheduler"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,21 +22,49 @@ func main() {
 	// Загрузка конфигурации
 	cfg := config.GetConfig()
 
+	// Загрузка ключей подписи JWT
+	if err := auth.LoadKeyring(); err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	// Загрузка настроек ботов Telegram
+	if err := config.LoadTelegramBots(); err != nil {
+		log.Fatalf("Failed to load Telegram bots: %v", err)
+	}
+
+	if cfg.DevAuthEnabled {
+		log.Println("WARNING: development auth mode is enabled, anyone can log in as any Telegram user")
+	}
+
 	// Инициализация подключения к базе данных
 	database.InitDB()
 	defer database.DB.Close()
 
+	// Запуск планировщика сессий
+	scheduler.Start(context.Background())
+
 	// Установка режима релиза для Gin
 	gin.SetMode(gin.ReleaseMode)
 
 	// Создание роутера Gin
 	router := gin.Default()
 
+	// IP адрес клиента (для ограничения частоты запросов и журнала аудита) берется из X-Forwarded-For
+	// только за доверенными прокси, по умолчанию - из соединения
+	var trustedProxies []string
+	if cfg.TrustedProxies != "" {
+		trustedProxies = strings.FieldsFunc(cfg.TrustedProxies, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Добавляем CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, "+handlers.TelegramBotHeader+", "+auth.APIKeyHeader)
+		c.Header("Access-Control-Expose-Headers", "X-Total-Count, Retry-After, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, "+auth.RefreshedTokenHeader)
 		c.Next()
 	})
 
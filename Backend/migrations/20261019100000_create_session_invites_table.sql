@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE session_invites (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    code VARCHAR(64) UNIQUE NOT NULL,
+    label VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    max_uses INTEGER,
+    uses_count INTEGER NOT NULL DEFAULT 0,
+    default_team VARCHAR(100) NOT NULL DEFAULT '',
+    default_clan VARCHAR(100) NOT NULL DEFAULT '',
+    created_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_session_invites_session_id ON session_invites(session_id);
+
+-- Запоминаем, через какое приглашение игрок попал в сессию, и его команду/клан
+ALTER TABLE player_sessions ADD COLUMN invite_id INTEGER REFERENCES session_invites(id) ON DELETE SET NULL;
+ALTER TABLE player_sessions ADD COLUMN team VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE player_sessions ADD COLUMN clan VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_player_sessions_invite_id ON player_sessions(invite_id);
+
+-- Переносим существующие реферальные ссылки в приглашения, чтобы они продолжили работать
+INSERT INTO session_invites (session_id, code, label, created_by)
+SELECT id, referral_link, 'Основная ссылка', architect_id
+FROM sessions
+WHERE referral_link IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE player_sessions DROP COLUMN IF EXISTS clan;
+ALTER TABLE player_sessions DROP COLUMN IF EXISTS team;
+ALTER TABLE player_sessions DROP COLUMN IF EXISTS invite_id;
+DROP TABLE IF EXISTS session_invites;
+-- +goose StatementEnd
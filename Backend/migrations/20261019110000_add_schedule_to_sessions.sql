@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'lobby';
+ALTER TABLE sessions ADD COLUMN starts_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE sessions ADD COLUMN ends_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE sessions ADD COLUMN start_notified_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE sessions ADD CONSTRAINT sessions_status_check
+    CHECK (status IN ('scheduled', 'lobby', 'active', 'finished'));
+ALTER TABLE sessions ADD CONSTRAINT sessions_schedule_check
+    CHECK (starts_at IS NULL OR ends_at IS NULL OR ends_at > starts_at);
+
+-- Индексы для выборок планировщика
+CREATE INDEX idx_sessions_status_starts_at ON sessions(status, starts_at);
+CREATE INDEX idx_sessions_status_ends_at ON sessions(status, ends_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sessions_status_ends_at;
+DROP INDEX IF EXISTS idx_sessions_status_starts_at;
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_schedule_check;
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_status_check;
+ALTER TABLE sessions DROP COLUMN IF EXISTS start_notified_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS ends_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS starts_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
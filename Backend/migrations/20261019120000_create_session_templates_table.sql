@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Вместимость и игровые настройки сессии (соотношение ролей, кулдауны, параметры приглашения)
+ALTER TABLE sessions ADD COLUMN max_players INTEGER;
+ALTER TABLE sessions ADD COLUMN settings JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE session_templates (
+    id SERIAL PRIMARY KEY,
+    owner_id INTEGER NOT NULL REFERENCES telegram_users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    max_players INTEGER,
+    settings JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_session_templates_owner_id ON session_templates(owner_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS session_templates;
+ALTER TABLE sessions DROP COLUMN IF EXISTS settings;
+ALTER TABLE sessions DROP COLUMN IF EXISTS max_players;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- player_sessions становится таблицей участников сессии: помимо игроков в ней хранятся
+-- со-архитекторы, модераторы и наблюдатели
+ALTER TABLE player_sessions ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'player';
+ALTER TABLE player_sessions ADD COLUMN granted_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL;
+
+ALTER TABLE player_sessions ADD CONSTRAINT player_sessions_role_check
+    CHECK (role IN ('co_architect', 'moderator', 'observer', 'player'));
+
+CREATE INDEX idx_player_sessions_session_id_role ON player_sessions(session_id, role);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_player_sessions_session_id_role;
+ALTER TABLE player_sessions DROP CONSTRAINT IF EXISTS player_sessions_role_check;
+ALTER TABLE player_sessions DROP COLUMN IF EXISTS granted_by;
+ALTER TABLE player_sessions DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
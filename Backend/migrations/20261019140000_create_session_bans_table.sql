@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE session_bans (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES telegram_users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL DEFAULT '',
+    banned_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    lifted_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- У пользователя может быть только один неснятый бан в сессии
+CREATE UNIQUE INDEX idx_session_bans_active ON session_bans(session_id, user_id) WHERE lifted_at IS NULL;
+CREATE INDEX idx_session_bans_session_id ON session_bans(session_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS session_bans;
+-- +goose StatementEnd
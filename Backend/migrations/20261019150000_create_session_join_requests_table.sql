@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE session_join_requests (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES telegram_users(id) ON DELETE CASCADE,
+    invite_id INTEGER REFERENCES session_invites(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    decided_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- У пользователя может быть только одна ожидающая заявка в сессию
+CREATE UNIQUE INDEX idx_session_join_requests_pending ON session_join_requests(session_id, user_id) WHERE status = 'pending';
+CREATE INDEX idx_session_join_requests_session_id_status ON session_join_requests(session_id, status);
+CREATE INDEX idx_session_join_requests_user_id ON session_join_requests(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS session_join_requests;
+ALTER TABLE sessions DROP COLUMN IF EXISTS requires_approval;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN pin_hash TEXT;
+
+-- Неудачные попытки ввода PIN-кода для ограничения подбора
+CREATE TABLE session_pin_failures (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES telegram_users(id) ON DELETE CASCADE,
+    attempted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_session_pin_failures_session_id_attempted_at ON session_pin_failures(session_id, attempted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS session_pin_failures;
+ALTER TABLE sessions DROP COLUMN IF EXISTS pin_hash;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE sessions ADD COLUMN deleted_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL;
+
+-- Завершенные игры можно перенести в архив
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_status_check;
+ALTER TABLE sessions ADD CONSTRAINT sessions_status_check
+    CHECK (status IN ('scheduled', 'lobby', 'active', 'finished', 'archived'));
+
+CREATE INDEX idx_sessions_deleted_at ON sessions(deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sessions_deleted_at;
+
+UPDATE sessions SET status = 'finished' WHERE status = 'archived';
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_status_check;
+ALTER TABLE sessions ADD CONSTRAINT sessions_status_check
+    CHECK (status IN ('scheduled', 'lobby', 'active', 'finished'));
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE sessions DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Игроки из импортированного списка, которые еще не открывали приложение.
+-- При первом входе пользователь с совпадающим Telegram ID или username добавляется в сессию.
+CREATE TABLE session_roster_entries (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    telegram_id BIGINT,
+    username VARCHAR(255),
+    team VARCHAR(100) NOT NULL DEFAULT '',
+    clan VARCHAR(100) NOT NULL DEFAULT '',
+    added_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    claimed_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (telegram_id IS NOT NULL OR username IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_session_roster_entries_telegram_id ON session_roster_entries(session_id, telegram_id)
+    WHERE telegram_id IS NOT NULL AND claimed_at IS NULL;
+CREATE UNIQUE INDEX idx_session_roster_entries_username ON session_roster_entries(session_id, username)
+    WHERE username IS NOT NULL AND claimed_at IS NULL;
+CREATE INDEX idx_session_roster_entries_pending_telegram_id ON session_roster_entries(telegram_id) WHERE claimed_at IS NULL;
+CREATE INDEX idx_session_roster_entries_pending_username ON session_roster_entries(username) WHERE claimed_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS session_roster_entries;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_sessions_public ON sessions(starts_at) WHERE is_public AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sessions_public;
+ALTER TABLE sessions DROP COLUMN IF EXISTS is_public;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Вход пользователя на устройстве. Все токены входа отзываются вместе с ним.
+CREATE TABLE auth_logins (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES telegram_users(id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoke_reason VARCHAR(50)
+);
+
+CREATE INDEX idx_auth_logins_user_id ON auth_logins(user_id) WHERE revoked_at IS NULL;
+
+-- Refresh токены хранятся в виде SHA-256 хеша. Использованный токен остается в таблице,
+-- чтобы обнаружить его повторное использование.
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    login_id VARCHAR(64) NOT NULL REFERENCES auth_logins(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_login_id ON refresh_tokens(login_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS auth_logins;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Версия прав пользователя увеличивается при каждом изменении роли или прав администратора.
+-- Токены со старой версией перевыпускаются с актуальными правами.
+ALTER TABLE telegram_users ADD COLUMN perm_version INTEGER NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE telegram_users DROP COLUMN IF EXISTS perm_version;
+-- +goose StatementEnd
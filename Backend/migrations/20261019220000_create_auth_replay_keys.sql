@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Использованные данные аутентификации Telegram (query_id или hash) до истечения срока их действия.
+-- Повторный вход с теми же данными отклоняется на любом экземпляре бэкенда.
+CREATE TABLE IF NOT EXISTS auth_replay_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_replay_keys_expires_at ON auth_replay_keys(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS auth_replay_keys;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Пользователи и сессии относятся к боту (арендатору), через которого они появились.
+-- Один и тот же человек, вошедший через разных ботов, - это разные пользователи.
+ALTER TABLE telegram_users ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE telegram_users DROP CONSTRAINT IF EXISTS telegram_users_telegram_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_telegram_users_tenant_telegram_id ON telegram_users(tenant_id, telegram_id);
+
+ALTER TABLE sessions ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_sessions_tenant_id ON sessions(tenant_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sessions_tenant_id;
+ALTER TABLE sessions DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS idx_telegram_users_tenant_telegram_id;
+ALTER TABLE telegram_users DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE telegram_users ADD CONSTRAINT telegram_users_telegram_id_key UNIQUE (telegram_id);
+-- +goose StatementEnd
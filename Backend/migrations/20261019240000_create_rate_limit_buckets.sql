@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Корзины токенов ограничения частоты запросов, общие для всех экземпляров бэкенда
+-- (используются при RATE_LIMIT_STORE=postgres)
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rate_limit_buckets;
+-- +goose StatementEnd
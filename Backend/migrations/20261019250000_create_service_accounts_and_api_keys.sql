@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сервисный аккаунт для интеграций без входа через Telegram. Аккаунт действует от имени
+-- собственного пользователя с ролью архитектора и отрицательным telegram_id.
+CREATE TABLE service_accounts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES telegram_users(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+    name VARCHAR(255) NOT NULL,
+    created_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    disabled_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_service_accounts_tenant_id ON service_accounts(tenant_id);
+
+-- API ключи сервисных аккаунтов. Ключ хранится в виде SHA-256 хеша, поиск выполняется по открытому префиксу.
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    service_account_id INTEGER NOT NULL REFERENCES service_accounts(id) ON DELETE CASCADE,
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_api_keys_service_account_id ON api_keys(service_account_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM telegram_users WHERE id IN (SELECT user_id FROM service_accounts);
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS service_accounts;
+-- +goose StatementEnd
@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Роли и их права. Глобальные роли (scope = 'global') назначаются пользователям через telegram_users.role,
+-- роль admin получают администраторы, роль user - все пользователи. Роли в сессии и шаблоне определяются
+-- участием в сессии и владением.
+CREATE TABLE roles (
+    scope VARCHAR(16) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, name)
+);
+
+CREATE TABLE role_permissions (
+    scope VARCHAR(16) NOT NULL,
+    role VARCHAR(64) NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (scope, role, permission),
+    FOREIGN KEY (scope, role) REFERENCES roles(scope, name) ON DELETE CASCADE
+);
+
+INSERT INTO roles (scope, name, description) VALUES
+    ('global', 'user', 'Любой пользователь'),
+    ('global', 'Архитектор', 'Создает сессии и шаблоны'),
+    ('global', 'admin', 'Администратор бота'),
+    ('session', 'owner', 'Архитектор, создавший сессию'),
+    ('session', 'co_architect', 'Полный контроль над сессией'),
+    ('session', 'moderator', 'Исключает игроков и исправляет состояния'),
+    ('session', 'observer', 'Только просмотр'),
+    ('session', 'player', 'Игрок'),
+    ('template', 'owner', 'Владелец шаблона');
+
+INSERT INTO role_permissions (scope, role, permission) VALUES
+    ('global', 'Архитектор', 'session.create'),
+    ('global', 'Архитектор', 'template.create'),
+    ('global', 'admin', 'session.create'),
+    ('global', 'admin', 'session.view'),
+    ('global', 'admin', 'session.moderate'),
+    ('global', 'admin', 'session.manage'),
+    ('global', 'admin', 'session.grant_roles'),
+    ('global', 'admin', 'session.restore'),
+    ('global', 'admin', 'template.create'),
+    ('global', 'admin', 'template.use'),
+    ('global', 'admin', 'users.view'),
+    ('global', 'admin', 'users.set_role'),
+    ('global', 'admin', 'roles.manage'),
+    ('global', 'admin', 'service_accounts.manage'),
+    ('global', 'admin', 'debug.view'),
+    ('session', 'owner', 'session.view'),
+    ('session', 'owner', 'session.moderate'),
+    ('session', 'owner', 'session.manage'),
+    ('session', 'owner', 'session.grant_roles'),
+    ('session', 'co_architect', 'session.view'),
+    ('session', 'co_architect', 'session.moderate'),
+    ('session', 'co_architect', 'session.manage'),
+    ('session', 'moderator', 'session.view'),
+    ('session', 'moderator', 'session.moderate'),
+    ('session', 'observer', 'session.view'),
+    ('session', 'player', 'session.view'),
+    ('template', 'owner', 'template.use');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Выдача и снятие прав администратора доступны администраторам
+INSERT INTO role_permissions (scope, role, permission) VALUES ('global', 'admin', 'users.manage_admins');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission = 'users.manage_admins';
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Блокировка пользователя во всем приложении (без срока - бессрочная)
+CREATE TABLE user_suspensions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES telegram_users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL DEFAULT '',
+    suspended_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    lifted_by INTEGER REFERENCES telegram_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- У пользователя может быть только одна неснятая блокировка
+CREATE UNIQUE INDEX idx_user_suspensions_active ON user_suspensions(user_id) WHERE lifted_at IS NULL;
+
+-- Блокировка пользователей доступна администраторам
+INSERT INTO role_permissions (scope, role, permission) VALUES ('global', 'admin', 'users.suspend');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission = 'users.suspend';
+DROP TABLE IF EXISTS user_suspensions;
+-- +goose StatementEnd
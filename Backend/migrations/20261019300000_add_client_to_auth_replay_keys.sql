@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Клиент (хеш User-Agent и IP адреса), первым использовавший данные аутентификации.
+-- Повторная отправка тех же данных этим клиентом разрешена, другим клиентом - отклоняется.
+ALTER TABLE auth_replay_keys ADD COLUMN client VARCHAR(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE auth_replay_keys DROP COLUMN IF EXISTS client;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Журнал аудита передается отдельной роли без входа. Бэкенд подключается ролью из prophecy_app,
+-- которая может только добавлять и читать записи: отключить или удалить триггеры журнала
+-- может только владелец таблицы. Миграцию выполняет суперпользователь или владелец базы данных,
+-- который может создавать роли и входит в prophecy_audit_owner.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'prophecy_app') THEN
+        CREATE ROLE prophecy_app NOLOGIN;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'prophecy_audit_owner') THEN
+        CREATE ROLE prophecy_audit_owner NOLOGIN;
+    END IF;
+END
+$$;
+
+-- Остальные таблицы бэкенд читает и изменяет, в том числе созданные следующими миграциями
+GRANT USAGE ON SCHEMA public TO prophecy_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO prophecy_app;
+GRANT USAGE, SELECT, UPDATE ON ALL SEQUENCES IN SCHEMA public TO prophecy_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO prophecy_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT, UPDATE ON SEQUENCES TO prophecy_app;
+REVOKE ALL ON goose_db_version FROM prophecy_app;
+
+-- В журнал аудита можно только добавлять записи и читать их
+REVOKE ALL ON audit_log FROM PUBLIC, prophecy_app;
+GRANT SELECT, INSERT ON audit_log TO prophecy_app;
+REVOKE ALL ON SEQUENCE audit_log_id_seq FROM PUBLIC, prophecy_app;
+GRANT USAGE ON SEQUENCE audit_log_id_seq TO prophecy_app;
+
+-- Выданные права сохраняются при смене владельца, последовательность переходит вместе с таблицей
+ALTER FUNCTION audit_log_append_only() OWNER TO prophecy_audit_owner;
+ALTER TABLE audit_log OWNER TO prophecy_audit_owner;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_log OWNER TO CURRENT_USER;
+ALTER FUNCTION audit_log_append_only() OWNER TO CURRENT_USER;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT, UPDATE ON SEQUENCES FROM prophecy_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM prophecy_app;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM prophecy_app;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM prophecy_app;
+REVOKE USAGE ON SCHEMA public FROM prophecy_app;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Данные аутентификации снова используются только один раз, независимо от клиента
+ALTER TABLE auth_replay_keys DROP COLUMN IF EXISTS client;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE auth_replay_keys ADD COLUMN client VARCHAR(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
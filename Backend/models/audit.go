@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditPlatformTenantID идентификатор журнала для действий, общих для всех ботов (например, изменение глобальных ролей).
+// Такой идентификатор не может быть у бота, а записи с ним видны в журнале каждого бота.
+const AuditPlatformTenantID = "*"
+
+// Кто выполнил действие из журнала аудита
+const (
+	AuditActorUser           = "user"            // пользователь с JWT токеном
+	AuditActorServiceAccount = "service_account" // сервисный аккаунт с API ключом
+	AuditActorSystem         = "system"          // бэкенд (запуск, вход администратора из списка бота)
+)
+
+// Действия журнала аудита
+const (
+	AuditUserSetRole           = "user.set_role"
+	AuditUserGrantAdmin        = "user.grant_admin"
+	AuditUserRevokeAdmin       = "user.revoke_admin"
+	AuditUserSuspend           = "user.suspend"
+	AuditUserLiftSuspension    = "user.lift_suspension"
+	AuditSessionDelete         = "session.delete"
+	AuditSessionRestore        = "session.restore"
+	AuditSessionRemovePlayer   = "session.remove_player"
+	AuditSessionUpdatePlayer   = "session.update_player"
+	AuditSessionBan            = "session.ban"
+	AuditSessionLiftBan        = "session.lift_ban"
+	AuditSessionSetMemberRole  = "session.set_member_role"
+	AuditSessionRevokeMember   = "session.revoke_member_role"
+	AuditRoleSave              = "role.save"
+	AuditRoleDelete            = "role.delete"
+	AuditServiceAccountCreate  = "service_account.create"
+	AuditServiceAccountDisable = "service_account.disable"
+	AuditAPIKeyCreate          = "api_key.create"
+	AuditAPIKeyRevoke          = "api_key.revoke"
+)
+
+// Типы объектов журнала аудита
+const (
+	AuditTargetUser           = "user"
+	AuditTargetSession        = "session"
+	AuditTargetRole           = "role"
+	AuditTargetServiceAccount = "service_account"
+	AuditTargetAPIKey         = "api_key"
+)
+
+// AuditEntry запись журнала привилегированных действий
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	TenantID   string          `json:"tenant_id"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    *int            `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter параметры выборки журнала аудита
+type AuditFilter struct {
+	TenantID   string
+	ActorID    int
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      *time.Time // записи не раньше
+	Until      *time.Time // записи раньше
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"prophecy/backend/database"
+	"strconv"
+	"strings"
+)
+
+// nullJSON возвращает JSON для записи в базу данных, пустое значение записывается как NULL
+func nullJSON(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
+
+// CreateAuditEntry добавляет запись в журнал аудита
+func CreateAuditEntry(entry *AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (tenant_id, actor_type, actor_id, action, target_type, target_id, before, after, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+
+	return database.DB.QueryRow(query,
+		entry.TenantID,
+		entry.ActorType,
+		entry.ActorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		nullJSON(entry.Before),
+		nullJSON(entry.After),
+		entry.IP,
+		entry.UserAgent,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// ListAuditEntries получает записи журнала аудита по фильтру, новые первыми, и их общее количество
+func ListAuditEntries(filter AuditFilter) ([]AuditEntry, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	// addArg добавляет аргумент запроса и возвращает его плейсхолдер
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions = append(conditions, "tenant_id IN ("+addArg(filter.TenantID)+", "+addArg(AuditPlatformTenantID)+")")
+	if filter.ActorID != 0 {
+		conditions = append(conditions, "actor_id = "+addArg(filter.ActorID))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+addArg(filter.Action))
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, "target_type = "+addArg(filter.TargetType))
+	}
+	if filter.TargetID != "" {
+		conditions = append(conditions, "target_id = "+addArg(filter.TargetID))
+	}
+	if filter.Since != nil {
+		conditions = append(conditions, "created_at >= "+addArg(*filter.Since))
+	}
+	if filter.Until != nil {
+		conditions = append(conditions, "created_at < "+addArg(*filter.Until))
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := database.DB.QueryRow(`SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, tenant_id, actor_type, actor_id, action, target_type, target_id, before, after, ip, user_agent, created_at
+		FROM audit_log` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ` + addArg(filter.Limit) + ` OFFSET ` + addArg(filter.Offset)
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		var before, after []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.TenantID,
+			&entry.ActorType,
+			&entry.ActorID,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&before,
+			&after,
+			&entry.IP,
+			&entry.UserAgent,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		entry.Before = before
+		entry.After = after
+		entries = append(entries, entry)
+	}
+
+	return entries, total, rows.Err()
+}
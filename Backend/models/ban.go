@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// SessionBan представляет бан пользователя в сессии
+type SessionBan struct {
+	ID        int        `json:"id"`
+	SessionID int        `json:"session_id"`
+	UserID    int        `json:"user_id"`
+	Reason    string     `json:"reason"`
+	BannedBy  *int       `json:"banned_by"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	LiftedAt  *time.Time `json:"lifted_at"`
+	LiftedBy  *int       `json:"lifted_by"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	UserName string `json:"user_name"` // сгенерированное имя забаненного пользователя
+}
+
+// IsActive проверяет, действует ли бан в указанный момент
+func (b *SessionBan) IsActive(now time.Time) bool {
+	if b.LiftedAt != nil {
+		return false
+	}
+	return b.ExpiresAt == nil || now.Before(*b.ExpiresAt)
+}
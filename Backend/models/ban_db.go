@@ -0,0 +1,144 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+)
+
+const sessionBanColumns = `b.id, b.session_id, b.user_id, b.reason, b.banned_by, b.expires_at, b.lifted_at, b.lifted_by, b.created_at, COALESCE(u.generated_name, '')`
+
+// scanSessionBan считывает бан из строки результата запроса
+func scanSessionBan(row interface{ Scan(...interface{}) error }) (*SessionBan, error) {
+	var ban SessionBan
+	err := row.Scan(
+		&ban.ID,
+		&ban.SessionID,
+		&ban.UserID,
+		&ban.Reason,
+		&ban.BannedBy,
+		&ban.ExpiresAt,
+		&ban.LiftedAt,
+		&ban.LiftedBy,
+		&ban.CreatedAt,
+		&ban.UserName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ban, nil
+}
+
+// CreateSessionBan банит пользователя в сессии и исключает его из неё.
+// Предыдущий неснятый бан (например, истекший) заменяется новым.
+func CreateSessionBan(ban *SessionBan) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	liftQuery := `
+		UPDATE session_bans
+		SET lifted_at = CURRENT_TIMESTAMP, lifted_by = $3
+		WHERE session_id = $1 AND user_id = $2 AND lifted_at IS NULL`
+
+	if _, err := tx.Exec(liftQuery, ban.SessionID, ban.UserID, ban.BannedBy); err != nil {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO session_bans (session_id, user_id, reason, banned_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err = tx.QueryRow(insertQuery, ban.SessionID, ban.UserID, ban.Reason, ban.BannedBy, ban.ExpiresAt).
+		Scan(&ban.ID, &ban.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM player_sessions WHERE player_id = $1 AND session_id = $2`, ban.UserID, ban.SessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetActiveSessionBan получает действующий бан пользователя в сессии или nil, если бана нет
+func GetActiveSessionBan(sessionID, userID int) (*SessionBan, error) {
+	query := `
+		SELECT ` + sessionBanColumns + `
+		FROM session_bans b
+		LEFT JOIN telegram_users u ON b.user_id = u.id
+		WHERE b.session_id = $1 AND b.user_id = $2
+		  AND b.lifted_at IS NULL
+		  AND (b.expires_at IS NULL OR b.expires_at > CURRENT_TIMESTAMP)`
+
+	ban, err := scanSessionBan(database.DB.QueryRow(query, sessionID, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return ban, err
+}
+
+// GetSessionBans получает баны сессии. Если activeOnly, возвращаются только действующие баны.
+func GetSessionBans(sessionID int, activeOnly bool) ([]SessionBan, error) {
+	query := `
+		SELECT ` + sessionBanColumns + `
+		FROM session_bans b
+		LEFT JOIN telegram_users u ON b.user_id = u.id
+		WHERE b.session_id = $1
+		  AND (NOT $2 OR (b.lifted_at IS NULL AND (b.expires_at IS NULL OR b.expires_at > CURRENT_TIMESTAMP)))
+		ORDER BY b.created_at DESC`
+
+	rows, err := database.DB.Query(query, sessionID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bans := []SessionBan{}
+	for rows.Next() {
+		ban, err := scanSessionBan(rows)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, *ban)
+	}
+
+	return bans, rows.Err()
+}
+
+// LiftSessionBan снимает бан пользователя в сессии. Возвращает false, если неснятого бана не было.
+func LiftSessionBan(sessionID, userID, liftedBy int) (bool, error) {
+	query := `
+		UPDATE session_bans
+		SET lifted_at = CURRENT_TIMESTAMP, lifted_by = $3
+		WHERE session_id = $1 AND user_id = $2 AND lifted_at IS NULL`
+
+	result, err := database.DB.Exec(query, sessionID, userID, liftedBy)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// hasActiveBanTx проверяет в транзакции, есть ли у пользователя действующий бан в сессии
+func hasActiveBanTx(tx *sql.Tx, sessionID, userID int) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM session_bans
+			WHERE session_id = $1 AND user_id = $2 AND lifted_at IS NULL
+			  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		)`
+
+	var banned bool
+	err := tx.QueryRow(query, sessionID, userID).Scan(&banned)
+	return banned, err
+}
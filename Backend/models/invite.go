@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Ошибки, возникающие при использовании приглашения
+var (
+	ErrInviteRevoked   = errors.New("invite has been revoked")
+	ErrInviteExpired   = errors.New("invite has expired")
+	ErrInviteExhausted = errors.New("invite has reached its maximum number of uses")
+)
+
+// SessionInvite представляет приглашение в сессию
+type SessionInvite struct {
+	ID          int        `json:"id"`
+	SessionID   int        `json:"session_id"`
+	Code        string     `json:"code"`
+	Label       string     `json:"label"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxUses     *int       `json:"max_uses"`
+	UsesCount   int        `json:"uses_count"`
+	DefaultTeam string     `json:"default_team"`
+	DefaultClan string     `json:"default_clan"`
+	CreatedBy   *int       `json:"created_by"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CheckUsable проверяет, можно ли присоединиться к сессии по приглашению
+func (i *SessionInvite) CheckUsable(now time.Time) error {
+	if i.RevokedAt != nil {
+		return ErrInviteRevoked
+	}
+	if i.ExpiresAt != nil && !now.Before(*i.ExpiresAt) {
+		return ErrInviteExpired
+	}
+	if i.MaxUses != nil && i.UsesCount >= *i.MaxUses {
+		return ErrInviteExhausted
+	}
+	return nil
+}
+
+// InvitePlayer представляет игрока, присоединившегося по приглашению
+type InvitePlayer struct {
+	TelegramUser
+	Team     string    `json:"team"`
+	Clan     string    `json:"clan"`
+	JoinedAt time.Time `json:"joined_at"`
+}
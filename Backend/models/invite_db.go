@@ -0,0 +1,207 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+	"time"
+)
+
+const sessionInviteColumns = `id, session_id, code, label, expires_at, max_uses, uses_count, default_team, default_clan, created_by, revoked_at, created_at`
+
+// scanSessionInvite считывает приглашение из строки результата запроса
+func scanSessionInvite(row interface{ Scan(...interface{}) error }) (*SessionInvite, error) {
+	var invite SessionInvite
+	err := row.Scan(
+		&invite.ID,
+		&invite.SessionID,
+		&invite.Code,
+		&invite.Label,
+		&invite.ExpiresAt,
+		&invite.MaxUses,
+		&invite.UsesCount,
+		&invite.DefaultTeam,
+		&invite.DefaultClan,
+		&invite.CreatedBy,
+		&invite.RevokedAt,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// CreateSessionInvite создает новое приглашение в сессию
+func CreateSessionInvite(invite *SessionInvite) error {
+	query := `
+		INSERT INTO session_invites (session_id, code, label, expires_at, max_uses, default_team, default_clan, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, uses_count, created_at`
+
+	return database.DB.QueryRow(query,
+		invite.SessionID,
+		invite.Code,
+		invite.Label,
+		invite.ExpiresAt,
+		invite.MaxUses,
+		invite.DefaultTeam,
+		invite.DefaultClan,
+		invite.CreatedBy,
+	).Scan(&invite.ID, &invite.UsesCount, &invite.CreatedAt)
+}
+
+// GetSessionInvites получает все приглашения сессии
+func GetSessionInvites(sessionID int) ([]SessionInvite, error) {
+	query := `
+		SELECT ` + sessionInviteColumns + `
+		FROM session_invites
+		WHERE session_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := database.DB.Query(query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []SessionInvite{}
+	for rows.Next() {
+		invite, err := scanSessionInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *invite)
+	}
+
+	return invites, rows.Err()
+}
+
+// GetSessionInviteByID получает приглашение по ID
+func GetSessionInviteByID(id int) (*SessionInvite, error) {
+	query := `SELECT ` + sessionInviteColumns + ` FROM session_invites WHERE id = $1`
+
+	invite, err := scanSessionInvite(database.DB.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return invite, err
+}
+
+// GetSessionInviteByCode получает приглашение по коду из ссылки
+func GetSessionInviteByCode(code string) (*SessionInvite, error) {
+	query := `SELECT ` + sessionInviteColumns + ` FROM session_invites WHERE code = $1`
+
+	invite, err := scanSessionInvite(database.DB.QueryRow(query, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return invite, err
+}
+
+// RevokeSessionInvite отзывает приглашение
+func RevokeSessionInvite(id int) error {
+	query := `UPDATE session_invites SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+	_, err := database.DB.Exec(query, id)
+	return err
+}
+
+// GetInvitePlayers получает игроков, присоединившихся по приглашению
+func GetInvitePlayers(inviteID int) ([]InvitePlayer, error) {
+	query := `
+		SELECT u.id, u.telegram_id, u.first_name, u.last_name, u.username, u.photo_url, u.auth_date, u.generated_name, u.is_admin, u.role, u.created_at,
+		       ps.team, ps.clan, ps.joined_at
+		FROM player_sessions ps
+		JOIN telegram_users u ON ps.player_id = u.id
+		WHERE ps.invite_id = $1 AND ` + notSuspendedCondition + `
+		ORDER BY ps.joined_at DESC`
+
+	rows, err := database.DB.Query(query, inviteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := []InvitePlayer{}
+	for rows.Next() {
+		var player InvitePlayer
+		err := rows.Scan(
+			&player.ID,
+			&player.TelegramID,
+			&player.FirstName,
+			&player.LastName,
+			&player.Username,
+			&player.PhotoURL,
+			&player.AuthDate,
+			&player.GeneratedName,
+			&player.IsAdmin,
+			&player.Role,
+			&player.CreatedAt,
+			&player.Team,
+			&player.Clan,
+			&player.JoinedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, player)
+	}
+
+	return players, rows.Err()
+}
+
+// JoinSessionWithInvite добавляет игрока в сессию по приглашению.
+// Счетчик использований увеличивается в той же транзакции, что и добавление игрока,
+// поэтому ограничение max_uses не может быть превышено при одновременных запросах.
+func JoinSessionWithInvite(playerID int, invite *SessionInvite) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkSessionCapacity(tx, invite.SessionID); err != nil {
+		return err
+	}
+
+	updateQuery := `
+		UPDATE session_invites
+		SET uses_count = uses_count + 1
+		WHERE id = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		  AND (max_uses IS NULL OR uses_count < max_uses)`
+
+	result, err := tx.Exec(updateQuery, invite.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		// Приглашение стало недействительным после того, как мы его прочитали
+		current, err := GetSessionInviteByID(invite.ID)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			if err := current.CheckUsable(time.Now()); err != nil {
+				return err
+			}
+		}
+		return ErrInviteExhausted
+	}
+
+	insertQuery := `
+		INSERT INTO player_sessions (player_id, session_id, invite_id, team, clan)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	if _, err := tx.Exec(insertQuery, playerID, invite.SessionID, invite.ID, invite.DefaultTeam, invite.DefaultClan); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Статусы заявки на вступление в сессию
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+// Ошибки обработки заявок на вступление
+var (
+	ErrJoinRequestNotFound   = errors.New("join request not found")
+	ErrJoinRequestNotPending = errors.New("join request has already been decided")
+	ErrUserBanned            = errors.New("user is banned from this session")
+)
+
+// JoinRequest представляет заявку пользователя на вступление в сессию
+type JoinRequest struct {
+	ID          int        `json:"id"`
+	SessionID   int        `json:"session_id"`
+	UserID      int        `json:"user_id"`
+	InviteID    *int       `json:"invite_id"`
+	Status      string     `json:"status"`
+	DecidedBy   *int       `json:"decided_by"`
+	DecidedAt   *time.Time `json:"decided_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UserName    string     `json:"user_name"`    // сгенерированное имя заявителя
+	SessionName string     `json:"session_name"` // название сессии
+	TelegramID  int64      `json:"-"`            // для уведомления заявителя
+}
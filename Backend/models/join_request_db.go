@@ -0,0 +1,239 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+	"time"
+)
+
+const joinRequestColumns = `r.id, r.session_id, r.user_id, r.invite_id, r.status, r.decided_by, r.decided_at, r.created_at, u.generated_name, s.name, u.telegram_id`
+
+const joinRequestFrom = `
+		FROM session_join_requests r
+		JOIN telegram_users u ON r.user_id = u.id
+		JOIN sessions s ON r.session_id = s.id`
+
+// scanJoinRequest считывает заявку из строки результата запроса
+func scanJoinRequest(row interface{ Scan(...interface{}) error }) (*JoinRequest, error) {
+	var request JoinRequest
+	err := row.Scan(
+		&request.ID,
+		&request.SessionID,
+		&request.UserID,
+		&request.InviteID,
+		&request.Status,
+		&request.DecidedBy,
+		&request.DecidedAt,
+		&request.CreatedAt,
+		&request.UserName,
+		&request.SessionName,
+		&request.TelegramID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// queryJoinRequests выполняет запрос, возвращающий список заявок
+func queryJoinRequests(query string, args ...interface{}) ([]JoinRequest, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []JoinRequest{}
+	for rows.Next() {
+		request, err := scanJoinRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	return requests, rows.Err()
+}
+
+// getJoinRequestByID получает заявку по ID
+func getJoinRequestByID(id int) (*JoinRequest, error) {
+	query := `SELECT ` + joinRequestColumns + joinRequestFrom + ` WHERE r.id = $1`
+
+	request, err := scanJoinRequest(database.DB.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return request, err
+}
+
+// CreateJoinRequest создает заявку на вступление в сессию.
+// Если у пользователя уже есть ожидающая заявка, возвращается она.
+func CreateJoinRequest(sessionID, userID int, inviteID *int) (*JoinRequest, error) {
+	query := `
+		INSERT INTO session_join_requests (session_id, user_id, invite_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (session_id, user_id) WHERE status = 'pending' DO NOTHING
+		RETURNING id`
+
+	var id int
+	err := database.DB.QueryRow(query, sessionID, userID, inviteID).Scan(&id)
+	if err == sql.ErrNoRows {
+		// Заявка уже существует
+		existingQuery := `SELECT ` + joinRequestColumns + joinRequestFrom + `
+			WHERE r.session_id = $1 AND r.user_id = $2 AND r.status = $3`
+		return scanJoinRequest(database.DB.QueryRow(existingQuery, sessionID, userID, JoinRequestPending))
+	} else if err != nil {
+		return nil, err
+	}
+
+	return getJoinRequestByID(id)
+}
+
+// GetSessionJoinRequests получает заявки сессии с указанным статусом (все, если статус пустой)
+func GetSessionJoinRequests(sessionID int, status string) ([]JoinRequest, error) {
+	query := `SELECT ` + joinRequestColumns + joinRequestFrom + `
+		WHERE r.session_id = $1 AND ($2 = '' OR r.status = $2)
+		ORDER BY r.created_at ASC`
+
+	return queryJoinRequests(query, sessionID, status)
+}
+
+// GetUserJoinRequests получает все заявки пользователя
+func GetUserJoinRequests(userID int) ([]JoinRequest, error) {
+	query := `SELECT ` + joinRequestColumns + joinRequestFrom + `
+		WHERE r.user_id = $1 AND s.deleted_at IS NULL
+		ORDER BY r.created_at DESC`
+
+	return queryJoinRequests(query, userID)
+}
+
+// lockPendingJoinRequest блокирует ожидающую заявку сессии до конца транзакции
+func lockPendingJoinRequest(tx *sql.Tx, requestID, sessionID int) (userID int, inviteID *int, err error) {
+	var status string
+	err = tx.QueryRow(`SELECT user_id, invite_id, status FROM session_join_requests WHERE id = $1 AND session_id = $2 FOR UPDATE`, requestID, sessionID).
+		Scan(&userID, &inviteID, &status)
+	if err == sql.ErrNoRows {
+		return 0, nil, ErrJoinRequestNotFound
+	} else if err != nil {
+		return 0, nil, err
+	}
+
+	if status != JoinRequestPending {
+		return 0, nil, ErrJoinRequestNotPending
+	}
+
+	return userID, inviteID, nil
+}
+
+// ApproveJoinRequest одобряет заявку и добавляет пользователя в сессию.
+// Приглашение, по которому подана заявка, проверяется повторно: с момента подачи
+// его могли отозвать, оно могло истечь или исчерпать лимит использований.
+func ApproveJoinRequest(requestID, sessionID, decidedBy int) (*JoinRequest, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	userID, inviteID, err := lockPendingJoinRequest(tx, requestID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	banned, err := hasActiveBanTx(tx, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if banned {
+		return nil, ErrUserBanned
+	}
+
+	if err := checkSessionCapacity(tx, sessionID); err != nil {
+		return nil, err
+	}
+
+	// Приглашение блокируется до конца транзакции, чтобы одновременные одобрения
+	// и вступления по нему не превысили max_uses. Команда и клан берутся из приглашения.
+	var invite *SessionInvite
+	if inviteID != nil {
+		invite, err = scanSessionInvite(tx.QueryRow(`SELECT `+sessionInviteColumns+` FROM session_invites WHERE id = $1 FOR UPDATE`, *inviteID))
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if invite != nil {
+			if err := invite.CheckUsable(time.Now()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	team, clan := "", ""
+	if invite != nil {
+		team, clan = invite.DefaultTeam, invite.DefaultClan
+	}
+
+	insertQuery := `
+		INSERT INTO player_sessions (player_id, session_id, invite_id, team, clan)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (player_id, session_id) DO NOTHING`
+
+	result, err := tx.Exec(insertQuery, userID, sessionID, inviteID, team, clan)
+	if err != nil {
+		return nil, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	// Использование приглашения засчитывается, только если пользователь действительно добавлен
+	if invite != nil && inserted > 0 {
+		if _, err := tx.Exec(`UPDATE session_invites SET uses_count = uses_count + 1 WHERE id = $1`, invite.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	updateQuery := `
+		UPDATE session_join_requests
+		SET status = $1, decided_by = $2, decided_at = CURRENT_TIMESTAMP
+		WHERE id = $3`
+
+	if _, err := tx.Exec(updateQuery, JoinRequestApproved, decidedBy, requestID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return getJoinRequestByID(requestID)
+}
+
+// RejectJoinRequest отклоняет заявку на вступление в сессию
+func RejectJoinRequest(requestID, sessionID, decidedBy int) (*JoinRequest, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, _, err := lockPendingJoinRequest(tx, requestID, sessionID); err != nil {
+		return nil, err
+	}
+
+	updateQuery := `
+		UPDATE session_join_requests
+		SET status = $1, decided_by = $2, decided_at = CURRENT_TIMESTAMP
+		WHERE id = $3`
+
+	if _, err := tx.Exec(updateQuery, JoinRequestRejected, decidedBy, requestID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return getJoinRequestByID(requestID)
+}
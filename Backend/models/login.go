@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Ошибки обновления токенов
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+	ErrLoginRevoked        = errors.New("login has been revoked")
+	ErrLoginExpired        = errors.New("login has expired")
+)
+
+// Причины отзыва входа
+const (
+	LoginRevokedLogout = "logout"      // пользователь вышел
+	LoginRevokedByUser = "revoked"     // пользователь отозвал вход с другого устройства
+	LoginRevokedReuse  = "token_reuse" // обнаружено повторное использование refresh токена
+)
+
+// Login представляет вход пользователя на устройстве
+type Login struct {
+	ID         string     `json:"id"`
+	UserID     int        `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Current    bool       `json:"current"` // вход, которым выполнен запрос
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+	"time"
+)
+
+const loginColumns = `id, user_id, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at`
+
+// CreateLogin создает вход пользователя вместе с первым refresh токеном
+func CreateLogin(login *Login, refreshTokenHash string) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO auth_logins (id, user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, last_used_at`
+
+	err = tx.QueryRow(query, login.ID, login.UserID, login.UserAgent, login.IPAddress, login.ExpiresAt).
+		Scan(&login.CreatedAt, &login.LastUsedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO refresh_tokens (login_id, token_hash) VALUES ($1, $2)`, login.ID, refreshTokenHash); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RotateRefreshToken помечает refresh токен использованным и сохраняет новый токен того же входа.
+// Повторное использование уже использованного токена означает, что токен украден,
+// поэтому вход отзывается целиком и возвращается ErrRefreshTokenReused вместе с отозванным входом.
+func RotateRefreshToken(tokenHash, newTokenHash string, expiresAt time.Time) (*Login, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var tokenID int
+	var usedAt *time.Time
+	var login Login
+	query := `
+		SELECT t.id, t.used_at, l.id, l.user_id, l.expires_at, l.revoked_at
+		FROM refresh_tokens t
+		JOIN auth_logins l ON t.login_id = l.id
+		WHERE t.token_hash = $1
+		FOR UPDATE OF t, l`
+
+	err = tx.QueryRow(query, tokenHash).Scan(&tokenID, &usedAt, &login.ID, &login.UserID, &login.ExpiresAt, &login.RevokedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrRefreshTokenInvalid
+	} else if err != nil {
+		return nil, err
+	}
+
+	if login.RevokedAt != nil {
+		return nil, ErrLoginRevoked
+	}
+
+	if usedAt != nil {
+		if err := revokeLoginTx(tx, login.ID, LoginRevokedReuse); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return &login, ErrRefreshTokenReused
+	}
+
+	if !login.ExpiresAt.After(time.Now()) {
+		return nil, ErrLoginExpired
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1`, tokenID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO refresh_tokens (login_id, token_hash) VALUES ($1, $2)`, login.ID, newTokenHash); err != nil {
+		return nil, err
+	}
+
+	// Вход продлевается при каждом обновлении токенов
+	err = tx.QueryRow(`UPDATE auth_logins SET last_used_at = CURRENT_TIMESTAMP, expires_at = $1 WHERE id = $2 RETURNING last_used_at`, expiresAt, login.ID).
+		Scan(&login.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	login.ExpiresAt = expiresAt
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &login, nil
+}
+
+// revokeLoginTx отзывает вход в транзакции
+func revokeLoginTx(tx *sql.Tx, loginID, reason string) error {
+	_, err := tx.Exec(`UPDATE auth_logins SET revoked_at = CURRENT_TIMESTAMP, revoke_reason = $1 WHERE id = $2 AND revoked_at IS NULL`, reason, loginID)
+	return err
+}
+
+// GetUserLogins получает действующие входы пользователя
+func GetUserLogins(userID int) ([]Login, error) {
+	query := `
+		SELECT ` + loginColumns + `
+		FROM auth_logins
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY last_used_at DESC`
+
+	rows, err := database.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logins := []Login{}
+	for rows.Next() {
+		var login Login
+		err := rows.Scan(
+			&login.ID,
+			&login.UserID,
+			&login.UserAgent,
+			&login.IPAddress,
+			&login.CreatedAt,
+			&login.LastUsedAt,
+			&login.ExpiresAt,
+			&login.RevokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		logins = append(logins, login)
+	}
+
+	return logins, rows.Err()
+}
+
+// IsLoginActive проверяет, что вход не отозван и не истек
+func IsLoginActive(loginID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM auth_logins WHERE id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP)`
+
+	var active bool
+	err := database.DB.QueryRow(query, loginID).Scan(&active)
+	return active, err
+}
+
+// RevokeLogin отзывает вход пользователя. Возвращает false, если действующего входа с таким ID нет.
+func RevokeLogin(userID int, loginID, reason string) (bool, error) {
+	query := `UPDATE auth_logins SET revoked_at = CURRENT_TIMESTAMP, revoke_reason = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`
+
+	result, err := database.DB.Exec(query, reason, loginID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RevokeUserLogins отзывает все входы пользователя, кроме exceptLoginID (пустая строка - отозвать все).
+// Возвращает ID отозванных входов.
+func RevokeUserLogins(userID int, exceptLoginID, reason string) ([]string, error) {
+	query := `
+		UPDATE auth_logins
+		SET revoked_at = CURRENT_TIMESTAMP, revoke_reason = $1
+		WHERE user_id = $2 AND revoked_at IS NULL AND id <> $3
+		RETURNING id`
+
+	rows, err := database.DB.Query(query, reason, userID, exceptLoginID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// PurgeStaleLogins удаляет входы, истекшие или отозванные раньше before, а также
+// использованные refresh токены старше before. Возвращает количество удаленных входов.
+func PurgeStaleLogins(before time.Time) (int64, error) {
+	result, err := database.DB.Exec(`DELETE FROM auth_logins WHERE expires_at < $1 OR revoked_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := database.DB.Exec(`DELETE FROM refresh_tokens WHERE used_at < $1`, before); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
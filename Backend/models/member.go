@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Роли участников сессии
+const (
+	SessionRoleCoArchitect = "co_architect" // полный контроль над сессией
+	SessionRoleModerator   = "moderator"    // может исключать игроков и исправлять состояния
+	SessionRoleObserver    = "observer"     // только просмотр, не является игроком
+	SessionRolePlayer      = "player"       // игрок
+)
+
+// IsValidSessionRole проверяет, является ли строка допустимой ролью участника сессии
+func IsValidSessionRole(role string) bool {
+	switch role {
+	case SessionRoleCoArchitect, SessionRoleModerator, SessionRoleObserver, SessionRolePlayer:
+		return true
+	}
+	return false
+}
+
+// PlayerState состояние игрока в сессии, которое могут исправлять модераторы
+type PlayerState struct {
+	Team string `json:"team"`
+	Clan string `json:"clan"`
+}
+
+// SessionMember представляет участника сессии и его роль в ней
+type SessionMember struct {
+	TelegramUser
+	SessionRole string    `json:"session_role"`
+	Team        string    `json:"team"`
+	Clan        string    `json:"clan"`
+	GrantedBy   *int      `json:"granted_by"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+)
+
+// GetSessionMemberRole получает роль пользователя в сессии или пустую строку, если он не участник
+func GetSessionMemberRole(sessionID, userID int) (string, error) {
+	query := `SELECT role FROM player_sessions WHERE session_id = $1 AND player_id = $2`
+
+	var role string
+	err := database.DB.QueryRow(query, sessionID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// GetSessionMembers получает всех участников сессии вместе с их ролями
+func GetSessionMembers(sessionID int) ([]SessionMember, error) {
+	query := `
+		SELECT u.id, u.telegram_id, u.first_name, u.last_name, u.username, u.photo_url, u.auth_date, u.generated_name, u.is_admin, u.role, u.created_at,
+		       ps.role, ps.team, ps.clan, ps.granted_by, ps.joined_at
+		FROM player_sessions ps
+		JOIN telegram_users u ON ps.player_id = u.id
+		WHERE ps.session_id = $1 AND ` + notSuspendedCondition + `
+		ORDER BY ps.joined_at DESC`
+
+	rows, err := database.DB.Query(query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []SessionMember{}
+	for rows.Next() {
+		var member SessionMember
+		err := rows.Scan(
+			&member.ID,
+			&member.TelegramID,
+			&member.FirstName,
+			&member.LastName,
+			&member.Username,
+			&member.PhotoURL,
+			&member.AuthDate,
+			&member.GeneratedName,
+			&member.IsAdmin,
+			&member.Role,
+			&member.CreatedAt,
+			&member.SessionRole,
+			&member.Team,
+			&member.Clan,
+			&member.GrantedBy,
+			&member.JoinedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// UpdatePlayerState исправляет команду и клан игрока в сессии (nil - значение не меняется).
+// Возвращает состояние до и после изменения или nil, если пользователь не является игроком сессии.
+func UpdatePlayerState(sessionID, playerID int, team, clan *string) (*PlayerState, *PlayerState, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var before PlayerState
+	selectQuery := `
+		SELECT team, clan FROM player_sessions
+		WHERE session_id = $1 AND player_id = $2 AND role = $3
+		FOR UPDATE`
+
+	err = tx.QueryRow(selectQuery, sessionID, playerID, SessionRolePlayer).Scan(&before.Team, &before.Clan)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	after := before
+	if team != nil {
+		after.Team = *team
+	}
+	if clan != nil {
+		after.Clan = *clan
+	}
+
+	updateQuery := `UPDATE player_sessions SET team = $3, clan = $4 WHERE session_id = $1 AND player_id = $2`
+	if _, err := tx.Exec(updateQuery, sessionID, playerID, after.Team, after.Clan); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return &before, &after, nil
+}
+
+// SetSessionMemberRole назначает пользователю роль в сессии, добавляя его в сессию при необходимости.
+// Назначение роли игрока учитывает вместимость сессии.
+func SetSessionMemberRole(sessionID, userID int, role string, grantedBy int) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if role == SessionRolePlayer {
+		var currentRole string
+		err := tx.QueryRow(`SELECT role FROM player_sessions WHERE session_id = $1 AND player_id = $2`, sessionID, userID).Scan(&currentRole)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		// Новый игрок занимает место в сессии
+		if currentRole != SessionRolePlayer {
+			if err := checkSessionCapacity(tx, sessionID); err != nil {
+				return err
+			}
+		}
+	}
+
+	query := `
+		INSERT INTO player_sessions (player_id, session_id, role, granted_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (player_id, session_id) DO UPDATE SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by`
+
+	if _, err := tx.Exec(query, userID, sessionID, role, grantedBy); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"prophecy/backend/database"
+	"time"
+)
+
+// SetSessionPin устанавливает хеш PIN-кода сессии (пустая строка снимает PIN).
+// Накопленные неудачные попытки сбрасываются вместе со сменой PIN-кода.
+func SetSessionPin(sessionID int, pinHash string) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE sessions SET pin_hash = NULLIF($1, ''), updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := tx.Exec(query, pinHash, sessionID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM session_pin_failures WHERE session_id = $1`, sessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReservePinAttempt атомарно резервирует попытку ввода PIN-кода до проверки самого PIN-кода.
+// Попытки сессии выполняются по очереди (строка сессии блокируется), поэтому одновременные
+// запросы не могут превысить лимит maxPerUser попыток пользователя с момента since.
+// Когда у всех пользователей сессии набирается maxPerSession попыток, остальные попытки замедляются:
+// пользователь без своих неудачных попыток может попробовать один раз, а повторные попытки отклоняются
+// до конца окна. Так перебор с нескольких аккаунтов не закрывает сессию для тех, кто знает PIN-код.
+// Попытки старше since удаляются. Возвращает false, если лимит исчерпан. Зарезервированная попытка остается неудачной,
+// пока ее не удалит ClearPinFailures после успешного ввода PIN-кода.
+func ReservePinAttempt(sessionID, userID int, since time.Time, maxPerUser, maxPerSession int) (bool, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM sessions WHERE id = $1 FOR UPDATE`, sessionID); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM session_pin_failures WHERE session_id = $1 AND attempted_at <= $2`, sessionID, since); err != nil {
+		return false, err
+	}
+
+	var userFailures, sessionFailures int
+	countQuery := `
+		SELECT COUNT(*) FILTER (WHERE user_id = $2), COUNT(*)
+		FROM session_pin_failures
+		WHERE session_id = $1`
+
+	if err := tx.QueryRow(countQuery, sessionID, userID).Scan(&userFailures, &sessionFailures); err != nil {
+		return false, err
+	}
+
+	if userFailures >= maxPerUser || (sessionFailures >= maxPerSession && userFailures > 0) {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`INSERT INTO session_pin_failures (session_id, user_id) VALUES ($1, $2)`, sessionID, userID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ClearPinFailures удаляет неудачные и зарезервированные попытки пользователя после успешного ввода PIN-кода
+func ClearPinFailures(sessionID, userID int) error {
+	_, err := database.DB.Exec(`DELETE FROM session_pin_failures WHERE session_id = $1 AND user_id = $2`, sessionID, userID)
+	return err
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"prophecy/backend/database"
+	"time"
+)
+
+// UpdateRateLimitBucket пересчитывает корзину токенов key под блокировкой строки.
+// Новая корзина создается с initialTokens токенами. Функция update получает текущее количество токенов
+// и время с последнего обновления по часам базы данных и возвращает новое количество токенов.
+func UpdateRateLimitBucket(key string, initialTokens float64, update func(tokens float64, elapsed time.Duration) float64) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO NOTHING`, key, initialTokens)
+	if err != nil {
+		return err
+	}
+
+	var tokens, elapsedSeconds float64
+	err = tx.QueryRow(`
+		SELECT tokens, GREATEST(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - updated_at)), 0)
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE`, key).Scan(&tokens, &elapsedSeconds)
+	if err != nil {
+		return err
+	}
+
+	tokens = update(tokens, time.Duration(elapsedSeconds*float64(time.Second)))
+
+	if _, err := tx.Exec(`UPDATE rate_limit_buckets SET tokens = $1, updated_at = CURRENT_TIMESTAMP WHERE key = $2`, tokens, key); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeStaleRateLimitBuckets удаляет корзины, которые не обновлялись с before. Возвращает количество удаленных корзин.
+func PurgeStaleRateLimitBuckets(before time.Time) (int64, error) {
+	result, err := database.DB.Exec(`DELETE FROM rate_limit_buckets WHERE updated_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
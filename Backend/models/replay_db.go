@@ -0,0 +1,36 @@
+package models
+
+import (
+	"prophecy/backend/database"
+	"time"
+)
+
+// ClaimAuthReplayKey отмечает данные аутентификации использованными до expiresAt.
+// Возвращает false, если ключ уже использован и его срок еще не истек.
+func ClaimAuthReplayKey(key string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO auth_replay_keys (key, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE auth_replay_keys.expires_at < NOW()`
+
+	result, err := database.DB.Exec(query, key, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// PurgeExpiredAuthReplayKeys удаляет ключи с истекшим сроком. Возвращает количество удаленных ключей.
+func PurgeExpiredAuthReplayKeys() (int64, error) {
+	result, err := database.DB.Exec(`DELETE FROM auth_replay_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
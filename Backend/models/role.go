@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// Области действия ролей
+const (
+	RoleScopeGlobal   = "global"   // роль пользователя во всем боте
+	RoleScopeSession  = "session"  // роль в конкретной сессии
+	RoleScopeTemplate = "template" // роль в конкретном шаблоне
+)
+
+// Встроенные глобальные роли
+const (
+	RoleUser      = "user"       // есть у каждого пользователя
+	RoleAdmin     = "admin"      // есть у администраторов
+	RoleArchitect = "Архитектор" // назначается через telegram_users.role
+)
+
+// Встроенные роли в сессии и шаблоне, не связанные с участием в сессии
+const (
+	RoleOwner = "owner" // создатель сессии или владелец шаблона
+)
+
+// Role роль и ее права
+type Role struct {
+	Scope       string    `json:"scope"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IsBuiltinGlobalRole проверяет, является ли глобальная роль встроенной и не назначаемой через telegram_users.role
+func IsBuiltinGlobalRole(name string) bool {
+	return name == RoleUser || name == RoleAdmin
+}
+
+// IsEditableGlobalRole проверяет, можно ли изменить или удалить глобальную роль.
+// Встроенные роли user, admin и Архитектор задают поведение по умолчанию для всех ботов и не изменяются.
+func IsEditableGlobalRole(name string) bool {
+	return !IsBuiltinGlobalRole(name) && name != RoleArchitect
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+
+	"github.com/lib/pq"
+)
+
+// GetRoles получает все роли вместе с их правами
+func GetRoles() ([]Role, error) {
+	query := `
+		SELECT r.scope, r.name, r.description, r.created_at,
+		       COALESCE(array_agg(p.permission ORDER BY p.permission) FILTER (WHERE p.permission IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions p ON p.scope = r.scope AND p.role = r.name
+		GROUP BY r.scope, r.name, r.description, r.created_at
+		ORDER BY r.scope, r.name`
+
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.Scope, &role.Name, &role.Description, &role.CreatedAt, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// GetRole получает роль вместе с ее правами. Возвращает nil, если роли нет.
+func GetRole(scope, name string) (*Role, error) {
+	query := `
+		SELECT r.scope, r.name, r.description, r.created_at,
+		       COALESCE(array_agg(p.permission ORDER BY p.permission) FILTER (WHERE p.permission IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions p ON p.scope = r.scope AND p.role = r.name
+		WHERE r.scope = $1 AND r.name = $2
+		GROUP BY r.scope, r.name, r.description, r.created_at`
+
+	var role Role
+	err := database.DB.QueryRow(query, scope, name).Scan(&role.Scope, &role.Name, &role.Description, &role.CreatedAt, pq.Array(&role.Permissions))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// RoleExists проверяет, существует ли роль
+func RoleExists(scope, name string) (bool, error) {
+	var exists bool
+	err := database.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM roles WHERE scope = $1 AND name = $2)`, scope, name).Scan(&exists)
+	return exists, err
+}
+
+// SaveRole создает роль или обновляет ее описание и полностью заменяет ее права
+func SaveRole(role *Role) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO roles (scope, name, description)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, name) DO UPDATE SET description = EXCLUDED.description
+		RETURNING created_at`,
+		role.Scope, role.Name, role.Description,
+	).Scan(&role.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE scope = $1 AND role = $2`, role.Scope, role.Name); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO role_permissions (scope, role, permission)
+		SELECT $1, $2, unnest($3::text[])`,
+		role.Scope, role.Name, pq.Array(role.Permissions))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteRole удаляет роль вместе с ее правами. Возвращает false, если роли нет.
+func DeleteRole(scope, name string) (bool, error) {
+	result, err := database.DB.Exec(`DELETE FROM roles WHERE scope = $1 AND name = $2`, scope, name)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// CountUsersWithRole получает количество пользователей всех ботов с глобальной ролью name.
+// Роли общие для всех ботов, поэтому удалить роль можно, только если она не назначена ни в одном боте.
+func CountUsersWithRole(name string) (int, error) {
+	var count int
+	err := database.DB.QueryRow(`SELECT COUNT(*) FROM telegram_users WHERE role = $1`, name).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Результаты обработки строки импортируемого списка игроков
+const (
+	RosterRowAdded         = "added"          // игрок добавлен в сессию
+	RosterRowAlreadyMember = "already_member" // пользователь уже участвует в сессии
+	RosterRowPending       = "pending"        // пользователь еще не входил в приложение, ожидает первого входа
+	RosterRowInvalid       = "invalid"        // строка не прошла проверку
+	RosterRowDuplicate     = "duplicate"      // пользователь уже встречался в файле
+	RosterRowBanned        = "banned"         // пользователь забанен в сессии
+	RosterRowSessionFull   = "session_full"   // в сессии не осталось мест
+)
+
+// RosterEntry представляет игрока из импортированного списка, ожидающего первого входа в приложение.
+// Username хранится в нижнем регистре без символа @.
+type RosterEntry struct {
+	ID         int        `json:"id"`
+	SessionID  int        `json:"session_id"`
+	TelegramID *int64     `json:"telegram_id"`
+	Username   *string    `json:"username"`
+	Team       string     `json:"team"`
+	Clan       string     `json:"clan"`
+	AddedBy    *int       `json:"added_by"`
+	ClaimedBy  *int       `json:"claimed_by"`
+	ClaimedAt  *time.Time `json:"claimed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// RosterRowResult результат обработки одной строки импортируемого списка игроков
+type RosterRowResult struct {
+	Row        int    `json:"row"`
+	Value      string `json:"value"`
+	Status     string `json:"status"`
+	UserID     *int   `json:"user_id,omitempty"`
+	TelegramID *int64 `json:"telegram_id,omitempty"`
+	Username   string `json:"username,omitempty"`
+	Team       string `json:"team,omitempty"`
+	Clan       string `json:"clan,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
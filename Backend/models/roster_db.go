@@ -0,0 +1,182 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"prophecy/backend/database"
+	"strings"
+)
+
+const rosterEntryColumns = `id, session_id, telegram_id, username, team, clan, added_by, claimed_by, claimed_at, created_at`
+
+// scanRosterEntry считывает запись списка игроков из строки результата запроса
+func scanRosterEntry(row interface{ Scan(...interface{}) error }) (*RosterEntry, error) {
+	var entry RosterEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.SessionID,
+		&entry.TelegramID,
+		&entry.Username,
+		&entry.Team,
+		&entry.Clan,
+		&entry.AddedBy,
+		&entry.ClaimedBy,
+		&entry.ClaimedAt,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// SaveRosterEntry сохраняет ожидающего игрока из импортированного списка.
+// Повторный импорт того же игрока обновляет его команду и клан.
+func SaveRosterEntry(entry *RosterEntry) error {
+	conflictTarget := `(session_id, telegram_id) WHERE telegram_id IS NOT NULL AND claimed_at IS NULL`
+	if entry.TelegramID == nil {
+		conflictTarget = `(session_id, username) WHERE username IS NOT NULL AND claimed_at IS NULL`
+	}
+
+	query := `
+		INSERT INTO session_roster_entries (session_id, telegram_id, username, team, clan, added_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT ` + conflictTarget + ` DO UPDATE SET team = EXCLUDED.team, clan = EXCLUDED.clan, added_by = EXCLUDED.added_by
+		RETURNING id, created_at`
+
+	return database.DB.QueryRow(query,
+		entry.SessionID,
+		entry.TelegramID,
+		entry.Username,
+		entry.Team,
+		entry.Clan,
+		entry.AddedBy,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetSessionRosterEntries получает игроков сессии, ожидающих первого входа в приложение
+func GetSessionRosterEntries(sessionID int) ([]RosterEntry, error) {
+	query := `
+		SELECT ` + rosterEntryColumns + `
+		FROM session_roster_entries
+		WHERE session_id = $1 AND claimed_at IS NULL
+		ORDER BY created_at ASC, id ASC`
+
+	rows, err := database.DB.Query(query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []RosterEntry{}
+	for rows.Next() {
+		entry, err := scanRosterEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// DeleteRosterEntry удаляет ожидающего игрока из списка сессии. Возвращает false, если запись не найдена.
+func DeleteRosterEntry(id, sessionID int) (bool, error) {
+	query := `DELETE FROM session_roster_entries WHERE id = $1 AND session_id = $2 AND claimed_at IS NULL`
+
+	result, err := database.DB.Exec(query, id, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ClaimRosterEntries добавляет пользователя в сессии, в списки которых он был импортирован по Telegram ID
+// или username до первого входа. Учитываются только сессии бота, через которого вошел пользователь.
+// Запись считается использованной, даже если пользователь забанен в сессии. Если в сессии нет мест,
+// запись остается ожидающей до следующего входа. Возвращает ID сессий, в которые пользователь добавлен.
+func ClaimRosterEntries(userID int, telegramID int64, username string) ([]int, error) {
+	query := `
+		SELECT r.id
+		FROM session_roster_entries r
+		JOIN sessions s ON r.session_id = s.id
+		WHERE r.claimed_at IS NULL
+		  AND s.deleted_at IS NULL
+		  AND s.status NOT IN ($3, $4)
+		  AND (r.telegram_id = $1 OR ($2 <> '' AND r.username = $2))
+		  AND s.tenant_id = (SELECT tenant_id FROM telegram_users WHERE id = $5)
+		ORDER BY r.id`
+
+	entryIDs, err := queryIDs(query, telegramID, strings.ToLower(username), SessionStatusFinished, SessionStatusArchived, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessionIDs []int
+	for _, entryID := range entryIDs {
+		sessionID, err := claimRosterEntry(entryID, userID)
+		if errors.Is(err, ErrSessionFull) {
+			continue
+		} else if err != nil {
+			return sessionIDs, err
+		}
+		if sessionID != 0 {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+
+	return sessionIDs, nil
+}
+
+// claimRosterEntry использует запись списка игроков для пользователя.
+// Возвращает ID сессии, если пользователь был добавлен в неё, и 0 в остальных случаях.
+func claimRosterEntry(entryID, userID int) (int, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var sessionID int
+	var team, clan string
+	err = tx.QueryRow(`SELECT session_id, team, clan FROM session_roster_entries WHERE id = $1 AND claimed_at IS NULL FOR UPDATE`, entryID).
+		Scan(&sessionID, &team, &clan)
+	if err == sql.ErrNoRows {
+		// Запись уже использована параллельным входом
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	banned, err := hasActiveBanTx(tx, sessionID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	added := false
+	if !banned {
+		added, err = addPlayerTx(tx, userID, sessionID, team, clan)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE session_roster_entries SET claimed_by = $1, claimed_at = CURRENT_TIMESTAMP WHERE id = $2`, userID, entryID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if !added {
+		return 0, nil
+	}
+	return sessionID, nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"prophecy/backend/database"
+	"time"
+)
+
+// Все функции этого файла меняют статус сессии условным UPDATE, который проверяет
+// текущий статус. Поэтому при нескольких экземплярах бэкенда каждый переход
+// выполняется ровно одним из них, а пропущенные переходы (например, если сервер
+// был выключен) выполняются при следующем запуске планировщика.
+
+// queryIDs выполняет запрос, возвращающий список ID
+func queryIDs(query string, args ...interface{}) ([]int, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// OpenDueLobbies открывает лобби запланированных сессий, до начала которых осталось меньше leadTime
+func OpenDueLobbies(leadTime time.Duration) ([]int, error) {
+	query := `
+		UPDATE sessions
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE status = $2 AND starts_at <= $3 AND deleted_at IS NULL
+		RETURNING id`
+
+	return queryIDs(query, SessionStatusLobby, SessionStatusScheduled, time.Now().Add(leadTime))
+}
+
+// StartDueSessions запускает игру в сессиях, время начала которых наступило
+func StartDueSessions() ([]int, error) {
+	query := `
+		UPDATE sessions
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE status IN ($2, $3) AND starts_at <= CURRENT_TIMESTAMP AND deleted_at IS NULL
+		RETURNING id`
+
+	return queryIDs(query, SessionStatusActive, SessionStatusScheduled, SessionStatusLobby)
+}
+
+// FinishDueSessions завершает сессии, время окончания которых наступило
+func FinishDueSessions() ([]int, error) {
+	query := `
+		UPDATE sessions
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE status NOT IN ($1, $2) AND ends_at <= CURRENT_TIMESTAMP AND deleted_at IS NULL
+		RETURNING id`
+
+	return queryIDs(query, SessionStatusFinished, SessionStatusArchived)
+}
+
+// ClaimStartNotifications отмечает сессии, игрокам которых пора отправить напоминание о начале.
+// Возвращает только те сессии, которые были отмечены этим вызовом, поэтому
+// напоминание отправляется один раз даже при нескольких экземплярах бэкенда.
+func ClaimStartNotifications(leadTime time.Duration) ([]Session, error) {
+	query := `
+		UPDATE sessions s
+		SET start_notified_at = CURRENT_TIMESTAMP
+		WHERE s.start_notified_at IS NULL
+		  AND s.status IN ($1, $2)
+		  AND s.starts_at > CURRENT_TIMESTAMP
+		  AND s.starts_at <= $3
+		  AND s.deleted_at IS NULL
+		RETURNING ` + sessionColumns
+
+	return querySessions(query, SessionStatusScheduled, SessionStatusLobby, time.Now().Add(leadTime))
+}
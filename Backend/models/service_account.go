@@ -0,0 +1,51 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Области доступа API ключей
+const (
+	ScopeSessionsRead  = "sessions:read"  // чтение сессий, игроков и результатов
+	ScopeSessionsWrite = "sessions:write" // создание и изменение сессий
+)
+
+// APIKeyScopes допустимые области доступа API ключей
+var APIKeyScopes = []string{ScopeSessionsRead, ScopeSessionsWrite}
+
+// ServiceAccount сервисный аккаунт для интеграций без входа через Telegram
+type ServiceAccount struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"` // пользователь, от имени которого действует аккаунт
+	TenantID   string     `json:"tenant_id"`
+	Name       string     `json:"name"`
+	CreatedBy  *int       `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+
+// APIKey ключ сервисного аккаунта. Сам ключ не хранится, только его хеш.
+type APIKey struct {
+	ID               int        `json:"id"`
+	ServiceAccountID int        `json:"service_account_id"`
+	Prefix           string     `json:"prefix"`
+	KeyHash          string     `json:"-"`
+	Scopes           []string   `json:"scopes"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	CreatedBy        *int       `json:"created_by"`
+	CreatedAt        time.Time  `json:"created_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	UserID           int        `json:"-"` // пользователь сервисного аккаунта, заполняется при проверке ключа
+}
+
+// HasScope проверяет, есть ли у ключа область доступа
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// IsExpired проверяет, истек ли срок действия ключа
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now())
+}
@@ -0,0 +1,230 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+
+	"github.com/lib/pq"
+)
+
+const serviceAccountColumns = `id, COALESCE(user_id, 0), tenant_id, name, created_by, created_at, disabled_at`
+
+const apiKeyColumns = `k.id, k.service_account_id, k.prefix, k.key_hash, k.scopes, k.expires_at, k.last_used_at, k.created_by, k.created_at, k.revoked_at`
+
+// scanServiceAccount считывает сервисный аккаунт из строки результата запроса
+func scanServiceAccount(row interface{ Scan(...interface{}) error }) (*ServiceAccount, error) {
+	var account ServiceAccount
+	err := row.Scan(
+		&account.ID,
+		&account.UserID,
+		&account.TenantID,
+		&account.Name,
+		&account.CreatedBy,
+		&account.CreatedAt,
+		&account.DisabledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// apiKeyScanDest возвращает указатели на поля ключа в порядке apiKeyColumns
+func apiKeyScanDest(key *APIKey) []interface{} {
+	return []interface{}{
+		&key.ID,
+		&key.ServiceAccountID,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.RevokedAt,
+	}
+}
+
+// CreateServiceAccount создает сервисный аккаунт вместе с его пользователем. Пользователь получает
+// роль архитектора и отрицательный telegram_id, чтобы не совпадать с пользователями Telegram.
+func CreateServiceAccount(account *ServiceAccount) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO service_accounts (tenant_id, name, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		account.TenantID, account.Name, account.CreatedBy,
+	).Scan(&account.ID, &account.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO telegram_users (telegram_id, tenant_id, first_name, generated_name, role)
+		VALUES ($1, $2, $3, $3, 'Архитектор')
+		RETURNING id`,
+		-int64(account.ID), account.TenantID, account.Name,
+	).Scan(&account.UserID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE service_accounts SET user_id = $1 WHERE id = $2`, account.UserID, account.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetServiceAccounts получает сервисные аккаунты бота tenantID
+func GetServiceAccounts(tenantID string) ([]ServiceAccount, error) {
+	query := `SELECT ` + serviceAccountColumns + ` FROM service_accounts WHERE tenant_id = $1 ORDER BY id`
+
+	rows, err := database.DB.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []ServiceAccount{}
+	for rows.Next() {
+		account, err := scanServiceAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// GetServiceAccountByID получает сервисный аккаунт бота tenantID по ID
+func GetServiceAccountByID(tenantID string, id int) (*ServiceAccount, error) {
+	query := `SELECT ` + serviceAccountColumns + ` FROM service_accounts WHERE id = $1 AND tenant_id = $2`
+
+	account, err := scanServiceAccount(database.DB.QueryRow(query, id, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return account, err
+}
+
+// DisableServiceAccount отключает сервисный аккаунт и отзывает все его ключи.
+// Возвращает false, если аккаунт уже отключен.
+func DisableServiceAccount(id int) (bool, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE service_accounts SET disabled_at = CURRENT_TIMESTAMP WHERE id = $1 AND disabled_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE service_account_id = $1 AND revoked_at IS NULL`, id); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// CreateAPIKey сохраняет новый ключ сервисного аккаунта
+func CreateAPIKey(key *APIKey) error {
+	query := `
+		INSERT INTO api_keys (service_account_id, prefix, key_hash, scopes, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	return database.DB.QueryRow(query,
+		key.ServiceAccountID,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(key.Scopes),
+		key.ExpiresAt,
+		key.CreatedBy,
+	).Scan(&key.ID, &key.CreatedAt)
+}
+
+// GetServiceAccountAPIKeys получает все ключи сервисного аккаунта, включая отозванные
+func GetServiceAccountAPIKeys(accountID int) ([]APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys k WHERE k.service_account_id = $1 ORDER BY k.id`
+
+	rows, err := database.DB.Query(query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		var key APIKey
+		if err := rows.Scan(apiKeyScanDest(&key)...); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetActiveAPIKeyByPrefix получает неотозванный ключ включенного сервисного аккаунта по префиксу
+// вместе с пользователем аккаунта. Срок действия ключа не проверяется.
+func GetActiveAPIKeyByPrefix(prefix string) (*APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `, a.user_id
+		FROM api_keys k
+		JOIN service_accounts a ON k.service_account_id = a.id
+		WHERE k.prefix = $1 AND k.revoked_at IS NULL AND a.disabled_at IS NULL AND a.user_id IS NOT NULL`
+
+	var key APIKey
+	err := database.DB.QueryRow(query, prefix).Scan(append(apiKeyScanDest(&key), &key.UserID)...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// TouchAPIKey обновляет время последнего использования ключа не чаще раза в минуту
+func TouchAPIKey(id int) error {
+	query := `
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')`
+
+	_, err := database.DB.Exec(query, id)
+	return err
+}
+
+// RevokeAPIKey отзывает ключ сервисного аккаунта. Возвращает false, если действующего ключа с таким ID нет.
+func RevokeAPIKey(accountID, keyID int) (bool, error) {
+	query := `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND service_account_id = $2 AND revoked_at IS NULL`
+
+	result, err := database.DB.Exec(query, keyID, accountID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -1,18 +1,69 @@
 package models
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrSessionFull возвращается при попытке добавить игрока в заполненную сессию
+var ErrSessionFull = errors.New("session is full")
+
+// ErrCapacityBelowPlayers возвращается при попытке сделать вместимость сессии меньше числа ее игроков
+var ErrCapacityBelowPlayers = errors.New("max players is below the current number of players")
+
+// ErrSessionStatusChanged возвращается, если статус сессии изменился (например, планировщиком),
+// пока менялось ее расписание
+var ErrSessionStatusChanged = errors.New("session status has changed")
+
+// Статусы жизненного цикла сессии
+const (
+	SessionStatusScheduled = "scheduled" // сессия запланирована, лобби еще не открыто
+	SessionStatusLobby     = "lobby"     // лобби открыто, игроки собираются
+	SessionStatusActive    = "active"    // игра идет
+	SessionStatusFinished  = "finished"  // игра завершена
+	SessionStatusArchived  = "archived"  // завершенная игра перенесена в архив
+)
+
 // Session представляет сессию/комнату, созданную архитектором
 type Session struct {
-	ID           int       `json:"id" db:"id"`
-	Name         string    `json:"name" db:"name"`
-	Description  string    `json:"description" db:"description"`
-	ArchitectID  int       `json:"architect_id" db:"architect_id"`
-	ReferralLink string    `json:"referral_link" db:"referral_link"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID               int             `json:"id" db:"id"`
+	Name             string          `json:"name" db:"name"`
+	Description      string          `json:"description" db:"description"`
+	ArchitectID      int             `json:"architect_id" db:"architect_id"`
+	TenantID         string          `json:"tenant_id" db:"tenant_id"`
+	ReferralLink     string          `json:"referral_link" db:"referral_link"`
+	Status           string          `json:"status" db:"status"`
+	StartsAt         *time.Time      `json:"starts_at" db:"starts_at"`
+	EndsAt           *time.Time      `json:"ends_at" db:"ends_at"`
+	StartNotifiedAt  *time.Time      `json:"-" db:"start_notified_at"`
+	MaxPlayers       *int            `json:"max_players" db:"max_players"`
+	RequiresApproval bool            `json:"requires_approval" db:"requires_approval"`
+	IsPublic         bool            `json:"is_public" db:"is_public"`
+	PinHash          string          `json:"-" db:"pin_hash"`
+	PinRequired      bool            `json:"pin_required"`
+	Settings         SessionSettings `json:"settings" db:"settings"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+	DeletedAt        *time.Time      `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// IsFinished проверяет, завершена ли игра в сессии (в том числе перенесенная в архив)
+func (s *Session) IsFinished() bool {
+	return s.Status == SessionStatusFinished || s.Status == SessionStatusArchived
+}
+
+// SessionUpdate изменения сессии. Поля со значением nil не изменяются.
+type SessionUpdate struct {
+	Name             *string
+	Description      *string
+	StartsAt         *time.Time // перенос начала снова переводит сессию в scheduled и сбрасывает напоминание
+	EndsAt           *time.Time
+	MaxPlayers       *int
+	Settings         *SessionSettings
+	RequiresApproval *bool
+	IsPublic         *bool
+	// Статус, в котором сессия была прочитана. Расписание меняется, только если статус с тех пор не изменился.
+	ExpectedStatus string
 }
 
 // SessionWithArchitect включает информацию об архитекторе
@@ -20,3 +71,35 @@ type SessionWithArchitect struct {
 	Session
 	ArchitectName string `json:"architect_name" db:"architect_name"`
 }
+
+// PublicSession описывает публичную сессию в списке открытых сессий
+type PublicSession struct {
+	ID               int        `json:"id"`
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	Status           string     `json:"status"`
+	StartsAt         *time.Time `json:"starts_at"`
+	MaxPlayers       *int       `json:"max_players"`
+	PlayersCount     int        `json:"players_count"`
+	RequiresApproval bool       `json:"requires_approval"`
+	PinRequired      bool       `json:"pin_required"`
+	ArchitectName    string     `json:"architect_name"`
+}
+
+// SessionFilter параметры выборки списка сессий
+type SessionFilter struct {
+	TenantID    string     // бот (арендатор), к которому относятся сессии
+	ManagedBy   int        // сессии, созданные пользователем или где у него есть особая роль
+	MemberID    int        // сессии, в которых пользователь участвует
+	Statuses    []string   // допустимые статусы (без них архивные сессии не выводятся)
+	Archived    bool       // включать архивные сессии, если статусы не указаны
+	Deleted     bool       // только удаленные сессии вместо неудаленных
+	ArchitectID int        // создатель сессии
+	CreatedFrom *time.Time // созданные не раньше
+	CreatedTo   *time.Time // созданные раньше
+	Search      string     // подстрока названия
+	SortBy      string     // created_at, updated_at, starts_at, name или status
+	SortDesc    bool
+	Limit       int
+	Offset      int
+}
@@ -3,31 +3,57 @@ package models
 import (
 	"database/sql"
 	"prophecy/backend/database"
+	"strconv"
+	"strings"
 	"time"
-)
 
-// CreateSession создает новую сессию в базе данных
-func CreateSession(session *Session) error {
-	query := `
-		INSERT INTO sessions (name, description, architect_id, referral_link)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at`
+	"github.com/lib/pq"
+)
 
-	err := database.DB.QueryRow(query, session.Name, session.Description, session.ArchitectID, session.ReferralLink).
-		Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+const sessionColumns = `s.id, s.name, COALESCE(s.description, ''), s.architect_id, s.tenant_id, COALESCE(s.referral_link, ''), s.status, s.starts_at, s.ends_at, s.start_notified_at, s.max_players, s.requires_approval, s.is_public, COALESCE(s.pin_hash, ''), s.pin_hash IS NOT NULL, s.settings, s.created_at, s.updated_at, s.deleted_at`
 
-	return err
+// sessionScanDest возвращает указатели на поля сессии в порядке sessionColumns
+func sessionScanDest(session *Session) []interface{} {
+	return []interface{}{
+		&session.ID,
+		&session.Name,
+		&session.Description,
+		&session.ArchitectID,
+		&session.TenantID,
+		&session.ReferralLink,
+		&session.Status,
+		&session.StartsAt,
+		&session.EndsAt,
+		&session.StartNotifiedAt,
+		&session.MaxPlayers,
+		&session.RequiresApproval,
+		&session.IsPublic,
+		&session.PinHash,
+		&session.PinRequired,
+		&session.Settings,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+		&session.DeletedAt,
+	}
 }
 
-// GetSessionsByArchitectID получает все сессии, созданные определенным архитектором
-func GetSessionsByArchitectID(architectID int) ([]Session, error) {
-	query := `
-		SELECT id, name, description, architect_id, referral_link, created_at, updated_at
-		FROM sessions
-		WHERE architect_id = $1
-		ORDER BY created_at DESC`
+// querySession выполняет запрос, возвращающий одну сессию
+func querySession(query string, args ...interface{}) (*Session, error) {
+	var session Session
+	err := database.DB.QueryRow(query, args...).Scan(sessionScanDest(&session)...)
 
-	rows, err := database.DB.Query(query, architectID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// querySessions выполняет запрос, возвращающий список сессий
+func querySessions(query string, args ...interface{}) ([]Session, error) {
+	rows, err := database.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -36,16 +62,7 @@ func GetSessionsByArchitectID(architectID int) ([]Session, error) {
 	var sessions []Session
 	for rows.Next() {
 		var session Session
-		err := rows.Scan(
-			&session.ID,
-			&session.Name,
-			&session.Description,
-			&session.ArchitectID,
-			&session.ReferralLink,
-			&session.CreatedAt,
-			&session.UpdatedAt,
-		)
-		if err != nil {
+		if err := rows.Scan(sessionScanDest(&session)...); err != nil {
 			return nil, err
 		}
 		sessions = append(sessions, session)
@@ -54,103 +71,504 @@ func GetSessionsByArchitectID(architectID int) ([]Session, error) {
 	return sessions, rows.Err()
 }
 
-// GetAllSessions получает все сессии (для админов)
-func GetAllSessions() ([]SessionWithArchitect, error) {
+// CreateSession создает новую сессию в базе данных вместе с ее основным приглашением
+func CreateSession(session *Session) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if session.Status == "" {
+		session.Status = SessionStatusLobby
+	}
+
 	query := `
-		SELECT s.id, s.name, s.description, s.architect_id, s.referral_link, s.created_at, s.updated_at, u.generated_name as architect_name
+		INSERT INTO sessions (name, description, architect_id, referral_link, status, starts_at, ends_at, max_players, requires_approval, settings, pin_hash, is_public, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12, $13)
+		RETURNING id, created_at, updated_at`
+
+	err = tx.QueryRow(query,
+		session.Name,
+		session.Description,
+		session.ArchitectID,
+		session.ReferralLink,
+		session.Status,
+		session.StartsAt,
+		session.EndsAt,
+		session.MaxPlayers,
+		session.RequiresApproval,
+		session.Settings,
+		session.PinHash,
+		session.IsPublic,
+		session.TenantID,
+	).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	session.PinRequired = session.PinHash != ""
+
+	// Основное приглашение использует тот же код, что и referral_link сессии,
+	// а его параметры берутся из настроек сессии
+	invite := SessionInvite{Label: "Основная ссылка"}
+	if settings := session.Settings.Invite; settings != nil {
+		if settings.Label != "" {
+			invite.Label = settings.Label
+		}
+		if settings.ExpiresInHours > 0 {
+			expiresAt := time.Now().Add(time.Duration(settings.ExpiresInHours) * time.Hour)
+			invite.ExpiresAt = &expiresAt
+		}
+		invite.MaxUses = settings.MaxUses
+		invite.DefaultTeam = settings.DefaultTeam
+		invite.DefaultClan = settings.DefaultClan
+	}
+
+	inviteQuery := `
+		INSERT INTO session_invites (session_id, code, label, expires_at, max_uses, default_team, default_clan, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = tx.Exec(inviteQuery,
+		session.ID,
+		session.ReferralLink,
+		invite.Label,
+		invite.ExpiresAt,
+		invite.MaxUses,
+		invite.DefaultTeam,
+		invite.DefaultClan,
+		session.ArchitectID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListSessions получает страницу сессий по фильтру и общее количество сессий, подходящих под фильтр
+func ListSessions(filter SessionFilter) ([]SessionWithArchitect, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	// addArg добавляет аргумент запроса и возвращает его плейсхолдер
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.TenantID != "" {
+		conditions = append(conditions, "s.tenant_id = "+addArg(filter.TenantID))
+	}
+	if filter.ManagedBy != 0 {
+		userArg := addArg(filter.ManagedBy)
+		conditions = append(conditions, `(s.architect_id = `+userArg+` OR EXISTS (
+			SELECT 1 FROM player_sessions ps
+			WHERE ps.session_id = s.id AND ps.player_id = `+userArg+` AND ps.role <> `+addArg(SessionRolePlayer)+`))`)
+	}
+	if filter.MemberID != 0 {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM player_sessions ps
+			WHERE ps.session_id = s.id AND ps.player_id = `+addArg(filter.MemberID)+`)`)
+	}
+	if filter.Deleted {
+		conditions = append(conditions, "s.deleted_at IS NOT NULL")
+	} else {
+		conditions = append(conditions, "s.deleted_at IS NULL")
+	}
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "s.status = ANY("+addArg(pq.Array(filter.Statuses))+")")
+	} else if !filter.Archived {
+		conditions = append(conditions, "s.status <> "+addArg(SessionStatusArchived))
+	}
+	if filter.ArchitectID != 0 {
+		conditions = append(conditions, "s.architect_id = "+addArg(filter.ArchitectID))
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "s.created_at >= "+addArg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "s.created_at < "+addArg(*filter.CreatedTo))
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "s.name ILIKE "+addArg("%"+escapeLike(filter.Search)+"%"))
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	from := `
 		FROM sessions s
 		JOIN telegram_users u ON s.architect_id = u.id
-		ORDER BY s.created_at DESC`
+		` + where
+
+	var total int
+	if err := database.DB.QueryRow(`SELECT COUNT(*) `+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Сортировка только по разрешенным колонкам, id делает порядок страниц стабильным
+	sortColumn, ok := sessionSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = sessionSortColumns["created_at"]
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	query := `SELECT ` + sessionColumns + `, u.generated_name as architect_name ` + from + `
+		ORDER BY ` + sortColumn + ` ` + direction + ` NULLS LAST, s.id ` + direction + `
+		LIMIT ` + addArg(filter.Limit) + ` OFFSET ` + addArg(filter.Offset)
 
-	rows, err := database.DB.Query(query)
+	rows, err := database.DB.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var sessions []SessionWithArchitect
+	sessions := []SessionWithArchitect{}
 	for rows.Next() {
 		var session SessionWithArchitect
+		if err := rows.Scan(append(sessionScanDest(&session.Session), &session.ArchitectName)...); err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, total, rows.Err()
+}
+
+// sessionSortColumns колонки, по которым можно сортировать список сессий
+var sessionSortColumns = map[string]string{
+	"created_at": "s.created_at",
+	"updated_at": "s.updated_at",
+	"starts_at":  "s.starts_at",
+	"name":       "s.name",
+	"status":     "s.status",
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
+
+// ListPublicSessions получает страницу открытых публичных сессий бота tenantID: не завершенных, со свободными местами
+// и созданных незаблокированными пользователями. Сессии отсортированы по времени начала, сессии без времени начала идут последними.
+func ListPublicSessions(tenantID, search string, limit, offset int) ([]PublicSession, int, error) {
+	from := `
+		FROM sessions s
+		JOIN telegram_users u ON s.architect_id = u.id
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS players_count
+			FROM player_sessions ps
+			WHERE ps.session_id = s.id AND ps.role = $1
+		) pc
+		WHERE s.is_public
+		  AND s.deleted_at IS NULL
+		  AND s.status IN ($2, $3, $4)
+		  AND (s.max_players IS NULL OR pc.players_count < s.max_players)
+		  AND ($5 = '' OR s.name ILIKE '%' || $5 || '%')
+		  AND s.tenant_id = $6
+		  AND ` + notSuspendedCondition
+
+	args := []interface{}{SessionRolePlayer, SessionStatusScheduled, SessionStatusLobby, SessionStatusActive, escapeLike(search), tenantID}
+
+	var total int
+	if err := database.DB.QueryRow(`SELECT COUNT(*) `+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT s.id, s.name, COALESCE(s.description, ''), s.status, s.starts_at, s.max_players, pc.players_count,
+		       s.requires_approval, s.pin_hash IS NOT NULL, u.generated_name
+		` + from + `
+		ORDER BY s.starts_at ASC NULLS LAST, s.id ASC
+		LIMIT $7 OFFSET $8`
+
+	rows, err := database.DB.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []PublicSession{}
+	for rows.Next() {
+		var session PublicSession
 		err := rows.Scan(
 			&session.ID,
 			&session.Name,
 			&session.Description,
-			&session.ArchitectID,
-			&session.ReferralLink,
-			&session.CreatedAt,
-			&session.UpdatedAt,
+			&session.Status,
+			&session.StartsAt,
+			&session.MaxPlayers,
+			&session.PlayersCount,
+			&session.RequiresApproval,
+			&session.PinRequired,
 			&session.ArchitectName,
 		)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		sessions = append(sessions, session)
 	}
 
-	return sessions, rows.Err()
+	return sessions, total, rows.Err()
 }
 
-// GetSessionByID получает сессию по ID
+// GetSessionByID получает сессию по ID (удаленные сессии не возвращаются)
 func GetSessionByID(id int) (*Session, error) {
 	query := `
-		SELECT id, name, description, architect_id, referral_link, created_at, updated_at
-		FROM sessions
-		WHERE id = $1`
+		SELECT ` + sessionColumns + `
+		FROM sessions s
+		WHERE s.id = $1 AND s.deleted_at IS NULL`
 
-	var session Session
-	err := database.DB.QueryRow(query, id).Scan(
-		&session.ID,
-		&session.Name,
-		&session.Description,
-		&session.ArchitectID,
-		&session.ReferralLink,
-		&session.CreatedAt,
-		&session.UpdatedAt,
-	)
+	return querySession(query, id)
+}
+
+// UpdateSession изменяет только переданные поля сессии и возвращает сессию после изменения
+// (nil, если сессия удалена). Остальные поля, в том числе статус, который меняет планировщик,
+// не перезаписываются. При изменении расписания сессия обновляется, только если ее статус
+// все еще update.ExpectedStatus, иначе возвращается ErrSessionStatusChanged.
+// Вместимость нельзя сделать меньше числа игроков (ErrCapacityBelowPlayers).
+func UpdateSession(id int, update SessionUpdate) (*Session, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Строка сессии блокируется так же, как при вступлении, поэтому игроки не могут
+	// присоединиться между проверкой и изменением вместимости
+	if update.MaxPlayers != nil {
+		if _, err := tx.Exec(`SELECT id FROM sessions WHERE id = $1 FOR UPDATE`, id); err != nil {
+			return nil, err
+		}
+
+		var count int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM player_sessions WHERE session_id = $1 AND role = $2`, id, SessionRolePlayer).Scan(&count)
+		if err != nil {
+			return nil, err
+		}
+
+		if count > *update.MaxPlayers {
+			return nil, ErrCapacityBelowPlayers
+		}
+	}
+
+	var assignments []string
+	var args []interface{}
+
+	// set добавляет присваивание колонке column значения value
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		assignments = append(assignments, column+" = $"+strconv.Itoa(len(args)))
+	}
 
+	if update.Name != nil {
+		set("name", *update.Name)
+	}
+	if update.Description != nil {
+		set("description", *update.Description)
+	}
+	if update.StartsAt != nil {
+		set("starts_at", *update.StartsAt)
+		set("status", SessionStatusScheduled)
+		assignments = append(assignments, "start_notified_at = NULL")
+	}
+	if update.EndsAt != nil {
+		set("ends_at", *update.EndsAt)
+	}
+	if update.MaxPlayers != nil {
+		set("max_players", *update.MaxPlayers)
+	}
+	if update.Settings != nil {
+		set("settings", *update.Settings)
+	}
+	if update.RequiresApproval != nil {
+		set("requires_approval", *update.RequiresApproval)
+	}
+	if update.IsPublic != nil {
+		set("is_public", *update.IsPublic)
+	}
+	assignments = append(assignments, "updated_at = CURRENT_TIMESTAMP")
+
+	args = append(args, id)
+	where := "s.id = $" + strconv.Itoa(len(args)) + " AND s.deleted_at IS NULL"
+
+	rescheduled := update.ExpectedStatus != "" && (update.StartsAt != nil || update.EndsAt != nil)
+	if rescheduled {
+		args = append(args, update.ExpectedStatus)
+		where += " AND s.status = $" + strconv.Itoa(len(args))
+	}
+
+	query := `
+		UPDATE sessions s
+		SET ` + strings.Join(assignments, ", ") + `
+		WHERE ` + where + `
+		RETURNING ` + sessionColumns
+
+	var session Session
+	err = tx.QueryRow(query, args...).Scan(sessionScanDest(&session)...)
 	if err == sql.ErrNoRows {
+		if rescheduled {
+			return nil, ErrSessionStatusChanged
+		}
 		return nil, nil
 	} else if err != nil {
 		return nil, err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return &session, nil
 }
 
-// UpdateSession обновляет информацию о сессии
-func UpdateSession(session *Session) error {
+// DeleteSession помечает сессию удаленной. Игроки и история сессии сохраняются
+// до восстановления сессии или окончательного удаления по истечении срока хранения.
+func DeleteSession(id, deletedBy int) error {
 	query := `
 		UPDATE sessions
-		SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3`
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL`
+	_, err := database.DB.Exec(query, id, deletedBy)
+	return err
+}
 
-	_, err := database.DB.Exec(query, session.Name, session.Description, session.ID)
+// RestoreSession восстанавливает удаленную сессию бота tenantID. Возвращает false, если удаленной сессии с таким ID нет.
+func RestoreSession(tenantID string, id int) (bool, error) {
+	query := `
+		UPDATE sessions
+		SET deleted_at = NULL, deleted_by = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL`
+
+	result, err := database.DB.Exec(query, id, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// SetSessionArchived переносит завершенную сессию в архив или возвращает её из архива.
+// Возвращает false, если сессия не находится в нужном для перехода статусе.
+func SetSessionArchived(id int, archived bool) (bool, error) {
+	from, to := SessionStatusFinished, SessionStatusArchived
+	if !archived {
+		from, to = SessionStatusArchived, SessionStatusFinished
+	}
+
+	query := `
+		UPDATE sessions
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3 AND deleted_at IS NULL`
+
+	result, err := database.DB.Exec(query, to, id, from)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// PurgeDeletedSessions окончательно удаляет сессии, удаленные раньше before, вместе со всеми связанными данными
+func PurgeDeletedSessions(before time.Time) ([]int, error) {
+	query := `DELETE FROM sessions WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING id`
+	return queryIDs(query, before)
+}
+
+// checkSessionCapacity блокирует строку сессии до конца транзакции и проверяет,
+// есть ли в сессии свободное место
+func checkSessionCapacity(tx *sql.Tx, sessionID int) error {
+	var maxPlayers sql.NullInt64
+	err := tx.QueryRow(`SELECT max_players FROM sessions WHERE id = $1 FOR UPDATE`, sessionID).Scan(&maxPlayers)
+	if err != nil {
+		return err
+	}
+
+	if !maxPlayers.Valid {
+		return nil
+	}
+
+	var count int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM player_sessions WHERE session_id = $1 AND role = $2`, sessionID, SessionRolePlayer).Scan(&count)
 	if err != nil {
 		return err
 	}
 
-	// Обновляем время обновления в объекте
-	session.UpdatedAt = time.Now()
+	if int64(count) >= maxPlayers.Int64 {
+		return ErrSessionFull
+	}
 
 	return nil
 }
 
-// DeleteSession удаляет сессию по ID
-func DeleteSession(id int) error {
-	query := `DELETE FROM sessions WHERE id = $1`
-	_, err := database.DB.Exec(query, id)
+// AddPlayerToSession добавляет игрока к сессии
+func AddPlayerToSession(playerID, sessionID int) error {
+	_, err := AddPlayerToSessionWithTeam(playerID, sessionID, "", "")
 	return err
 }
 
-// AddPlayerToSession добавляет игрока к сессии
-func AddPlayerToSession(playerID, sessionID int) error {
+// AddPlayerToSessionWithTeam добавляет игрока к сессии с указанными командой и кланом.
+// Возвращает false, если игрок уже участвует в сессии (повторное добавление ничего не меняет).
+func AddPlayerToSessionWithTeam(playerID, sessionID int, team, clan string) (bool, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	added, err := addPlayerTx(tx, playerID, sessionID, team, clan)
+	if err != nil || !added {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// addPlayerTx добавляет игрока к сессии в транзакции с проверкой вместимости.
+// Возвращает false, если пользователь уже участвует в сессии.
+func addPlayerTx(tx *sql.Tx, playerID, sessionID int, team, clan string) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM player_sessions WHERE player_id = $1 AND session_id = $2)`, playerID, sessionID).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	if err := checkSessionCapacity(tx, sessionID); err != nil {
+		return false, err
+	}
+
 	query := `
-		INSERT INTO player_sessions (player_id, session_id)
-		VALUES ($1, $2)
+		INSERT INTO player_sessions (player_id, session_id, team, clan)
+		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (player_id, session_id) DO NOTHING`
 
-	_, err := database.DB.Exec(query, playerID, sessionID)
-	return err
+	result, err := tx.Exec(query, playerID, sessionID, team, clan)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
 }
 
 // RemovePlayerFromSession удаляет игрока из сессии
@@ -160,16 +578,16 @@ func RemovePlayerFromSession(playerID, sessionID int) error {
 	return err
 }
 
-// GetSessionPlayers получает всех игроков в сессии
+// GetSessionPlayers получает всех игроков в сессии (без со-архитекторов, модераторов и наблюдателей)
 func GetSessionPlayers(sessionID int) ([]TelegramUser, error) {
 	query := `
 		SELECT u.id, u.telegram_id, u.first_name, u.last_name, u.username, u.photo_url, u.auth_date, u.generated_name, u.is_admin, u.role, u.created_at
 		FROM player_sessions ps
 		JOIN telegram_users u ON ps.player_id = u.id
-		WHERE ps.session_id = $1
+		WHERE ps.session_id = $1 AND ps.role = $2 AND ` + notSuspendedCondition + `
 		ORDER BY ps.joined_at DESC`
 
-	rows, err := database.DB.Query(query, sessionID)
+	rows, err := database.DB.Query(query, sessionID, SessionRolePlayer)
 	if err != nil {
 		return nil, err
 	}
@@ -200,41 +618,6 @@ func GetSessionPlayers(sessionID int) ([]TelegramUser, error) {
 	return players, rows.Err()
 }
 
-// GetPlayerSessions получает все сессии, в которых участвует игрок
-func GetPlayerSessions(playerID int) ([]Session, error) {
-	query := `
-		SELECT s.id, s.name, s.description, s.architect_id, s.created_at, s.updated_at
-		FROM player_sessions ps
-		JOIN sessions s ON ps.session_id = s.id
-		WHERE ps.player_id = $1
-		ORDER BY s.created_at DESC`
-
-	rows, err := database.DB.Query(query, playerID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var sessions []Session
-	for rows.Next() {
-		var session Session
-		err := rows.Scan(
-			&session.ID,
-			&session.Name,
-			&session.Description,
-			&session.ArchitectID,
-			&session.CreatedAt,
-			&session.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		sessions = append(sessions, session)
-	}
-
-	return sessions, rows.Err()
-}
-
 // IsPlayerInSession проверяет, участвует ли игрок в сессии
 func IsPlayerInSession(playerID, sessionID int) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM player_sessions WHERE player_id = $1 AND session_id = $2)`
@@ -251,26 +634,9 @@ func IsPlayerInSession(playerID, sessionID int) (bool, error) {
 // GetSessionByReferralLink получает сессию по реферальной ссылке
 func GetSessionByReferralLink(referralLink string) (*Session, error) {
 	query := `
-		SELECT id, name, description, architect_id, referral_link, created_at, updated_at
-		FROM sessions
-		WHERE referral_link = $1`
-
-	var session Session
-	err := database.DB.QueryRow(query, referralLink).Scan(
-		&session.ID,
-		&session.Name,
-		&session.Description,
-		&session.ArchitectID,
-		&session.ReferralLink,
-		&session.CreatedAt,
-		&session.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	} else if err != nil {
-		return nil, err
-	}
+		SELECT ` + sessionColumns + `
+		FROM sessions s
+		WHERE s.referral_link = $1 AND s.deleted_at IS NULL`
 
-	return &session, nil
+	return querySession(query, referralLink)
 }
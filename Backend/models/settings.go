@@ -0,0 +1,67 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SessionSettings игровые настройки сессии, хранящиеся в JSONB
+type SessionSettings struct {
+	RoleRatios map[string]int  `json:"role_ratios,omitempty"` // относительное количество игроков каждой роли
+	Cooldowns  map[string]int  `json:"cooldowns,omitempty"`   // кулдауны действий в секундах
+	Invite     *InviteSettings `json:"invite,omitempty"`      // параметры основного приглашения
+}
+
+// InviteSettings параметры основного приглашения, создаваемого вместе с сессией
+type InviteSettings struct {
+	Label          string `json:"label,omitempty"`
+	ExpiresInHours int    `json:"expires_in_hours,omitempty"`
+	MaxUses        *int   `json:"max_uses,omitempty"`
+	DefaultTeam    string `json:"default_team,omitempty"`
+	DefaultClan    string `json:"default_clan,omitempty"`
+}
+
+// Validate проверяет корректность настроек
+func (s SessionSettings) Validate() error {
+	for role, ratio := range s.RoleRatios {
+		if ratio < 0 {
+			return fmt.Errorf("role ratio for %q must not be negative", role)
+		}
+	}
+	for action, seconds := range s.Cooldowns {
+		if seconds < 0 {
+			return fmt.Errorf("cooldown for %q must not be negative", action)
+		}
+	}
+	if s.Invite != nil {
+		if s.Invite.ExpiresInHours < 0 {
+			return errors.New("invite expires_in_hours must not be negative")
+		}
+		if s.Invite.MaxUses != nil && *s.Invite.MaxUses <= 0 {
+			return errors.New("invite max_uses must be positive")
+		}
+	}
+	return nil
+}
+
+// Value сериализует настройки для записи в базу данных
+func (s SessionSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan десериализует настройки из базы данных
+func (s *SessionSettings) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*s = SessionSettings{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("unsupported type for SessionSettings: %T", src)
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUserSuspended пользователь заблокирован во всем приложении
+var ErrUserSuspended = errors.New("user is suspended")
+
+// UserSuspension представляет блокировку пользователя во всем приложении
+type UserSuspension struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	Reason      string     `json:"reason"`
+	SuspendedBy *int       `json:"suspended_by"`
+	ExpiresAt   *time.Time `json:"expires_at"` // nil - бессрочная блокировка
+	LiftedAt    *time.Time `json:"lifted_at"`
+	LiftedBy    *int       `json:"lifted_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	UserName string `json:"user_name"` // сгенерированное имя заблокированного пользователя
+}
+
+// IsActive проверяет, действует ли блокировка в указанный момент
+func (s *UserSuspension) IsActive(now time.Time) bool {
+	if s.LiftedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
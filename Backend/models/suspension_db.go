@@ -0,0 +1,161 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+)
+
+const userSuspensionColumns = `s.id, s.user_id, s.reason, s.suspended_by, s.expires_at, s.lifted_at, s.lifted_by, s.created_at, COALESCE(u.generated_name, '')`
+
+// notSuspendedCondition условие запроса, скрывающее заблокированных пользователей из списков сессий
+// (таблица telegram_users должна быть доступна как u)
+const notSuspendedCondition = `NOT EXISTS (
+	SELECT 1 FROM user_suspensions us
+	WHERE us.user_id = u.id AND us.lifted_at IS NULL
+	  AND (us.expires_at IS NULL OR us.expires_at > CURRENT_TIMESTAMP))`
+
+// scanUserSuspension считывает блокировку из строки результата запроса
+func scanUserSuspension(row interface{ Scan(...interface{}) error }) (*UserSuspension, error) {
+	var suspension UserSuspension
+	err := row.Scan(
+		&suspension.ID,
+		&suspension.UserID,
+		&suspension.Reason,
+		&suspension.SuspendedBy,
+		&suspension.ExpiresAt,
+		&suspension.LiftedAt,
+		&suspension.LiftedBy,
+		&suspension.CreatedAt,
+		&suspension.UserName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &suspension, nil
+}
+
+// bumpPermVersion увеличивает версию прав пользователя, чтобы его токены были перевыпущены с актуальным состоянием
+func bumpPermVersion(tx *sql.Tx, userID int) error {
+	_, err := tx.Exec(`UPDATE telegram_users SET perm_version = perm_version + 1 WHERE id = $1`, userID)
+	return err
+}
+
+// CreateUserSuspension блокирует пользователя. Предыдущая неснятая блокировка (например, истекшая) заменяется новой.
+// Версия прав пользователя увеличивается, как и при других изменениях прав.
+func CreateUserSuspension(suspension *UserSuspension) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	liftQuery := `
+		UPDATE user_suspensions
+		SET lifted_at = CURRENT_TIMESTAMP, lifted_by = $2
+		WHERE user_id = $1 AND lifted_at IS NULL`
+
+	if _, err := tx.Exec(liftQuery, suspension.UserID, suspension.SuspendedBy); err != nil {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO user_suspensions (user_id, reason, suspended_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	err = tx.QueryRow(insertQuery, suspension.UserID, suspension.Reason, suspension.SuspendedBy, suspension.ExpiresAt).
+		Scan(&suspension.ID, &suspension.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := bumpPermVersion(tx, suspension.UserID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetActiveUserSuspension получает действующую блокировку пользователя или nil, если блокировки нет
+func GetActiveUserSuspension(userID int) (*UserSuspension, error) {
+	query := `
+		SELECT ` + userSuspensionColumns + `
+		FROM user_suspensions s
+		LEFT JOIN telegram_users u ON s.user_id = u.id
+		WHERE s.user_id = $1
+		  AND s.lifted_at IS NULL
+		  AND (s.expires_at IS NULL OR s.expires_at > CURRENT_TIMESTAMP)`
+
+	suspension, err := scanUserSuspension(database.DB.QueryRow(query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return suspension, err
+}
+
+// GetUserSuspensions получает блокировки пользователей бота tenantID, новые первыми.
+// Если userID не 0, возвращаются блокировки только этого пользователя.
+// Если activeOnly, возвращаются только действующие блокировки.
+func GetUserSuspensions(tenantID string, userID int, activeOnly bool) ([]UserSuspension, error) {
+	query := `
+		SELECT ` + userSuspensionColumns + `
+		FROM user_suspensions s
+		JOIN telegram_users u ON s.user_id = u.id
+		WHERE u.tenant_id = $1
+		  AND ($2 = 0 OR s.user_id = $2)
+		  AND (NOT $3 OR (s.lifted_at IS NULL AND (s.expires_at IS NULL OR s.expires_at > CURRENT_TIMESTAMP)))
+		ORDER BY s.created_at DESC`
+
+	rows, err := database.DB.Query(query, tenantID, userID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suspensions := []UserSuspension{}
+	for rows.Next() {
+		suspension, err := scanUserSuspension(rows)
+		if err != nil {
+			return nil, err
+		}
+		suspensions = append(suspensions, *suspension)
+	}
+
+	return suspensions, rows.Err()
+}
+
+// LiftUserSuspension снимает блокировку пользователя и увеличивает версию его прав.
+// Возвращает false, если действующей блокировки не было.
+func LiftUserSuspension(userID, liftedBy int) (bool, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE user_suspensions
+		SET lifted_at = CURRENT_TIMESTAMP, lifted_by = $2
+		WHERE user_id = $1 AND lifted_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`
+
+	result, err := tx.Exec(query, userID, liftedBy)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	if err := bumpPermVersion(tx, userID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
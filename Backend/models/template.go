@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// SessionTemplate представляет сохраненную конфигурацию сессии
+type SessionTemplate struct {
+	ID          int             `json:"id"`
+	OwnerID     int             `json:"owner_id"`
+	TenantID    string          `json:"-"` // бот владельца, заполняется только при получении из базы данных
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	MaxPlayers  *int            `json:"max_players"`
+	Settings    SessionSettings `json:"settings"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql"
+	"prophecy/backend/database"
+	"time"
+)
+
+const sessionTemplateColumns = `id, owner_id, (SELECT u.tenant_id FROM telegram_users u WHERE u.id = owner_id), name, description, max_players, settings, created_at, updated_at`
+
+// scanSessionTemplate считывает шаблон из строки результата запроса
+func scanSessionTemplate(row interface{ Scan(...interface{}) error }) (*SessionTemplate, error) {
+	var template SessionTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.OwnerID,
+		&template.TenantID,
+		&template.Name,
+		&template.Description,
+		&template.MaxPlayers,
+		&template.Settings,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateSessionTemplate создает новый шаблон сессии
+func CreateSessionTemplate(template *SessionTemplate) error {
+	query := `
+		INSERT INTO session_templates (owner_id, name, description, max_players, settings)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	return database.DB.QueryRow(query,
+		template.OwnerID,
+		template.Name,
+		template.Description,
+		template.MaxPlayers,
+		template.Settings,
+	).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+}
+
+// GetSessionTemplateByID получает шаблон по ID
+func GetSessionTemplateByID(id int) (*SessionTemplate, error) {
+	query := `SELECT ` + sessionTemplateColumns + ` FROM session_templates WHERE id = $1`
+
+	template, err := scanSessionTemplate(database.DB.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return template, err
+}
+
+// GetSessionTemplates получает шаблоны владельца или все шаблоны бота tenantID, если ownerID равен 0
+func GetSessionTemplates(tenantID string, ownerID int) ([]SessionTemplate, error) {
+	query := `
+		SELECT ` + sessionTemplateColumns + `
+		FROM session_templates
+		WHERE owner_id IN (SELECT id FROM telegram_users WHERE tenant_id = $1)
+		  AND ($2 = 0 OR owner_id = $2)
+		ORDER BY created_at DESC`
+
+	rows, err := database.DB.Query(query, tenantID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []SessionTemplate{}
+	for rows.Next() {
+		template, err := scanSessionTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	return templates, rows.Err()
+}
+
+// UpdateSessionTemplate обновляет шаблон сессии
+func UpdateSessionTemplate(template *SessionTemplate) error {
+	query := `
+		UPDATE session_templates
+		SET name = $1, description = $2, max_players = $3, settings = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5`
+
+	_, err := database.DB.Exec(query,
+		template.Name,
+		template.Description,
+		template.MaxPlayers,
+		template.Settings,
+		template.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	template.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// DeleteSessionTemplate удаляет шаблон сессии
+func DeleteSessionTemplate(id int) error {
+	query := `DELETE FROM session_templates WHERE id = $1`
+	_, err := database.DB.Exec(query, id)
+	return err
+}
@@ -1,9 +1,13 @@
 package models
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrLastAdmin попытка снять права с последнего администратора бота
+var ErrLastAdmin = errors.New("cannot revoke the last admin")
+
 // User представляет собой модель пользователя
 type User struct {
 	ID        int       `json:"id"`
@@ -25,6 +29,7 @@ type UserResponse struct {
 type TelegramUser struct {
 	ID            int       `json:"id"`
 	TelegramID    int64     `json:"telegram_id"`
+	TenantID      string    `json:"tenant_id"`
 	FirstName     string    `json:"first_name"`
 	LastName      string    `json:"last_name"`
 	Username      string    `json:"username"`
@@ -34,4 +39,13 @@ type TelegramUser struct {
 	IsAdmin       bool      `json:"is_admin"`
 	Role          string    `json:"role"`
 	CreatedAt     time.Time `json:"created_at"`
+	PermVersion   int       `json:"-"` // версия прав, заполняется только при получении одного пользователя
+}
+
+// UserPermissions актуальные права пользователя
+type UserPermissions struct {
+	IsAdmin     bool
+	Role        string
+	PermVersion int
+	Suspension  *UserSuspension // действующая блокировка пользователя или nil
 }
@@ -54,13 +54,14 @@ func GetUserByID(id int) (*User, error) {
 // CreateTelegramUser создает нового пользователя Telegram в базе данных
 func CreateTelegramUser(telegramUser *TelegramUser) error {
 	query := `
-		INSERT INTO telegram_users (telegram_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id`
+		INSERT INTO telegram_users (telegram_id, tenant_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, perm_version`
 
 	now := time.Now()
 	err := database.DB.QueryRow(query,
 		telegramUser.TelegramID,
+		telegramUser.TenantID,
 		telegramUser.FirstName,
 		telegramUser.LastName,
 		telegramUser.Username,
@@ -70,7 +71,7 @@ func CreateTelegramUser(telegramUser *TelegramUser) error {
 		telegramUser.IsAdmin,
 		telegramUser.Role,
 		now,
-	).Scan(&telegramUser.ID)
+	).Scan(&telegramUser.ID, &telegramUser.PermVersion)
 
 	if err != nil {
 		return err
@@ -81,17 +82,18 @@ func CreateTelegramUser(telegramUser *TelegramUser) error {
 	return nil
 }
 
-// GetTelegramUserByTelegramID получает пользователя Telegram по его Telegram ID
-func GetTelegramUserByTelegramID(telegramID int64) (*TelegramUser, error) {
+// GetTelegramUserByTelegramID получает пользователя Telegram бота tenantID по его Telegram ID
+func GetTelegramUserByTelegramID(tenantID string, telegramID int64) (*TelegramUser, error) {
 	var telegramUser TelegramUser
 	query := `
-		SELECT id, telegram_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at
+		SELECT id, telegram_id, tenant_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at, perm_version
 		FROM telegram_users
-		WHERE telegram_id = $1`
+		WHERE tenant_id = $1 AND telegram_id = $2`
 
-	err := database.DB.QueryRow(query, telegramID).Scan(
+	err := database.DB.QueryRow(query, tenantID, telegramID).Scan(
 		&telegramUser.ID,
 		&telegramUser.TelegramID,
+		&telegramUser.TenantID,
 		&telegramUser.FirstName,
 		&telegramUser.LastName,
 		&telegramUser.Username,
@@ -101,6 +103,7 @@ func GetTelegramUserByTelegramID(telegramID int64) (*TelegramUser, error) {
 		&telegramUser.IsAdmin,
 		&telegramUser.Role,
 		&telegramUser.CreatedAt,
+		&telegramUser.PermVersion,
 	)
 
 	if err != nil {
@@ -113,16 +116,18 @@ func GetTelegramUserByTelegramID(telegramID int64) (*TelegramUser, error) {
 	return &telegramUser, nil
 }
 
-// GetAllTelegramUsers получает всех пользователей Telegram из базы данных с пагинацией
-func GetAllTelegramUsers(limit, offset int) ([]TelegramUser, error) {
+// GetAllTelegramUsers получает всех пользователей Telegram бота tenantID из базы данных с пагинацией.
+// Пользователи сервисных аккаунтов (с отрицательным telegram_id) не включаются.
+func GetAllTelegramUsers(tenantID string, limit, offset int) ([]TelegramUser, error) {
 	var users []TelegramUser
 	query := `
-		SELECT id, telegram_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at
+		SELECT id, telegram_id, tenant_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at
 		FROM telegram_users
+		WHERE tenant_id = $1 AND telegram_id > 0
 		ORDER BY id ASC
-		LIMIT $1 OFFSET $2`
+		LIMIT $2 OFFSET $3`
 
-	rows, err := database.DB.Query(query, limit, offset)
+	rows, err := database.DB.Query(query, tenantID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +138,7 @@ func GetAllTelegramUsers(limit, offset int) ([]TelegramUser, error) {
 		err := rows.Scan(
 			&user.ID,
 			&user.TelegramID,
+			&user.TenantID,
 			&user.FirstName,
 			&user.LastName,
 			&user.Username,
@@ -158,18 +164,18 @@ func GetAllTelegramUsers(limit, offset int) ([]TelegramUser, error) {
 
 // SetUserRole устанавливает роль пользователю по его ID
 func SetUserRole(userID int, role string) error {
-	query := `UPDATE telegram_users SET role = $1 WHERE id = $2`
+	query := `UPDATE telegram_users SET role = $1, perm_version = perm_version + 1 WHERE id = $2`
 	_, err := database.DB.Exec(query, role, userID)
 	return err
 }
 
-// GetUserStats получает статистику пользователей
-func GetUserStats() (map[string]interface{}, error) {
+// GetUserStats получает статистику пользователей бота tenantID
+func GetUserStats(tenantID string) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// Получаем общее количество пользователей
 	var totalUsers int
-	err := database.DB.QueryRow("SELECT COUNT(*) FROM telegram_users").Scan(&totalUsers)
+	err := database.DB.QueryRow("SELECT COUNT(*) FROM telegram_users WHERE tenant_id = $1 AND telegram_id > 0", tenantID).Scan(&totalUsers)
 	if err != nil {
 		return nil, err
 	}
@@ -177,7 +183,7 @@ func GetUserStats() (map[string]interface{}, error) {
 
 	// Получаем количество администраторов
 	var adminUsers int
-	err = database.DB.QueryRow("SELECT COUNT(*) FROM telegram_users WHERE is_admin = true").Scan(&adminUsers)
+	err = database.DB.QueryRow("SELECT COUNT(*) FROM telegram_users WHERE tenant_id = $1 AND is_admin = true", tenantID).Scan(&adminUsers)
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +191,7 @@ func GetUserStats() (map[string]interface{}, error) {
 
 	// Получаем количество пользователей по ролям
 	roleStats := make(map[string]int)
-	rows, err := database.DB.Query("SELECT role, COUNT(*) FROM telegram_users WHERE role != '' GROUP BY role")
+	rows, err := database.DB.Query("SELECT role, COUNT(*) FROM telegram_users WHERE tenant_id = $1 AND telegram_id > 0 AND role != '' GROUP BY role", tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -210,17 +216,26 @@ func GetUserStats() (map[string]interface{}, error) {
 	return stats, nil
 }
 
+// SetUserAdmin устанавливает или снимает права администратора пользователю по его ID.
+// Версия прав увеличивается, только если права действительно изменились.
+func SetUserAdmin(userID int, isAdmin bool) error {
+	query := `UPDATE telegram_users SET is_admin = $1, perm_version = perm_version + 1 WHERE id = $2 AND is_admin <> $1`
+	_, err := database.DB.Exec(query, isAdmin, userID)
+	return err
+}
+
 // GetTelegramUserByID получает пользователя Telegram по его внутреннему ID
 func GetTelegramUserByID(id int) (*TelegramUser, error) {
 	var telegramUser TelegramUser
 	query := `
-		SELECT id, telegram_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at
+		SELECT id, telegram_id, tenant_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at, perm_version
 		FROM telegram_users
 		WHERE id = $1`
 
 	err := database.DB.QueryRow(query, id).Scan(
 		&telegramUser.ID,
 		&telegramUser.TelegramID,
+		&telegramUser.TenantID,
 		&telegramUser.FirstName,
 		&telegramUser.LastName,
 		&telegramUser.Username,
@@ -230,6 +245,7 @@ func GetTelegramUserByID(id int) (*TelegramUser, error) {
 		&telegramUser.IsAdmin,
 		&telegramUser.Role,
 		&telegramUser.CreatedAt,
+		&telegramUser.PermVersion,
 	)
 
 	if err != nil {
@@ -241,3 +257,136 @@ func GetTelegramUserByID(id int) (*TelegramUser, error) {
 
 	return &telegramUser, nil
 }
+
+// GetTelegramUserByUsername получает пользователя Telegram бота tenantID по username без учета регистра
+func GetTelegramUserByUsername(tenantID, username string) (*TelegramUser, error) {
+	var telegramUser TelegramUser
+	query := `
+		SELECT id, telegram_id, tenant_id, first_name, last_name, username, photo_url, auth_date, generated_name, is_admin, role, created_at, perm_version
+		FROM telegram_users
+		WHERE tenant_id = $1 AND LOWER(username) = LOWER($2)
+		LIMIT 1`
+
+	err := database.DB.QueryRow(query, tenantID, username).Scan(
+		&telegramUser.ID,
+		&telegramUser.TelegramID,
+		&telegramUser.TenantID,
+		&telegramUser.FirstName,
+		&telegramUser.LastName,
+		&telegramUser.Username,
+		&telegramUser.PhotoURL,
+		&telegramUser.AuthDate,
+		&telegramUser.GeneratedName,
+		&telegramUser.IsAdmin,
+		&telegramUser.Role,
+		&telegramUser.CreatedAt,
+		&telegramUser.PermVersion,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &telegramUser, nil
+}
+
+// RevokeUserAdmin снимает права администратора с пользователя бота tenantID.
+// Возвращает false, если пользователь не является администратором, и ErrLastAdmin,
+// если у бота не останется незаблокированных администраторов. Администраторы бота блокируются до конца транзакции,
+// чтобы одновременные запросы не сняли права со всех администраторов.
+func RevokeUserAdmin(tenantID string, userID int) (bool, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT u.id, ` + notSuspendedCondition + `
+		FROM telegram_users u
+		WHERE u.tenant_id = $1 AND u.is_admin
+		ORDER BY u.id
+		FOR UPDATE OF u`
+
+	rows, err := tx.Query(query, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	// Заблокированные администраторы не могут управлять ботом, поэтому не учитываются
+	isAdmin := false
+	otherActiveAdmins := 0
+	for rows.Next() {
+		var id int
+		var active bool
+		if err := rows.Scan(&id, &active); err != nil {
+			rows.Close()
+			return false, err
+		}
+		if id == userID {
+			isAdmin = true
+		} else if active {
+			otherActiveAdmins++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	if !isAdmin {
+		return false, nil
+	}
+	if otherActiveAdmins == 0 {
+		return false, ErrLastAdmin
+	}
+
+	if _, err := tx.Exec(`UPDATE telegram_users SET is_admin = FALSE, perm_version = perm_version + 1 WHERE id = $1`, userID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// GetUserPermissions получает актуальные права пользователя. Возвращает nil, если пользователь не найден.
+func GetUserPermissions(userID int) (*UserPermissions, error) {
+	var permissions UserPermissions
+	var suspensionID sql.NullInt64
+	var suspensionCreatedAt sql.NullTime
+	var suspension UserSuspension
+	query := `
+		SELECT u.is_admin, u.role, u.perm_version, s.id, COALESCE(s.reason, ''), s.expires_at, s.created_at
+		FROM telegram_users u
+		LEFT JOIN user_suspensions s ON s.user_id = u.id
+			AND s.lifted_at IS NULL
+			AND (s.expires_at IS NULL OR s.expires_at > CURRENT_TIMESTAMP)
+		WHERE u.id = $1`
+
+	err := database.DB.QueryRow(query, userID).Scan(
+		&permissions.IsAdmin,
+		&permissions.Role,
+		&permissions.PermVersion,
+		&suspensionID,
+		&suspension.Reason,
+		&suspension.ExpiresAt,
+		&suspensionCreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if suspensionID.Valid {
+		suspension.ID = int(suspensionID.Int64)
+		suspension.UserID = userID
+		suspension.CreatedAt = suspensionCreatedAt.Time
+		permissions.Suspension = &suspension
+	}
+
+	return &permissions, nil
+}
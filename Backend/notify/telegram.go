@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"prophecy/backend/config"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// SendTelegramMessage отправляет сообщение пользователю через Telegram Bot API от имени бота tenantID
+func SendTelegramMessage(tenantID string, chatID int64, text string) error {
+	bot := config.GetTelegramBot(tenantID)
+	if bot == nil || bot.Token == "" {
+		return fmt.Errorf("telegram bot %s is not configured", tenantID)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := "https://api.telegram.org/bot" + bot.Token + "/sendMessage"
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram API returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package policy
+
+import "slices"
+
+// Action именованное право. Роли получают права через таблицу role_permissions.
+type Action string
+
+// Права, не связанные с конкретным ресурсом
+const (
+	SessionCreate         Action = "session.create"          // создание и клонирование сессий
+	SessionRestore        Action = "session.restore"         // просмотр и восстановление удаленных сессий
+	TemplateCreate        Action = "template.create"         // работа с шаблонами сессий
+	UsersView             Action = "users.view"              // список пользователей, статистика и сессии любого игрока
+	UsersSetRole          Action = "users.set_role"          // назначение глобальной роли пользователю
+	UsersManageAdmins     Action = "users.manage_admins"     // выдача и снятие прав администратора
+	UsersSuspend          Action = "users.suspend"           // блокировка пользователей во всем приложении
+	RolesManage           Action = "roles.manage"            // изменение ролей и их прав
+	ServiceAccountsManage Action = "service_accounts.manage" // сервисные аккаунты и API ключи
+	DebugView             Action = "debug.view"              // метрики expvar
+	AuditView             Action = "audit.view"              // журнал аудита
+)
+
+// Права на сессию. Выданные глобальной ролью действуют на все сессии бота.
+const (
+	SessionView       Action = "session.view"        // просмотр сессии, ее игроков и участников
+	SessionModerate   Action = "session.moderate"    // исключение и баны игроков, исправление их команды и клана
+	SessionManage     Action = "session.manage"      // изменение, удаление, приглашения и заявки
+	SessionGrantRoles Action = "session.grant_roles" // назначение ролей в сессии и исключение участников с ролями
+)
+
+// Права на шаблон. Выданные глобальной ролью действуют на все шаблоны бота.
+const (
+	TemplateUse Action = "template.use" // использование и изменение шаблона
+)
+
+// Actions все известные права
+var Actions = []Action{
+	SessionCreate,
+	SessionRestore,
+	TemplateCreate,
+	UsersView,
+	UsersSetRole,
+	UsersManageAdmins,
+	UsersSuspend,
+	RolesManage,
+	ServiceAccountsManage,
+	DebugView,
+	AuditView,
+	SessionView,
+	SessionModerate,
+	SessionManage,
+	SessionGrantRoles,
+	TemplateUse,
+}
+
+// IsKnownAction проверяет, существует ли право с таким именем
+func IsKnownAction(name string) bool {
+	return slices.Contains(Actions, Action(name))
+}
@@ -0,0 +1,59 @@
+package policy
+
+import (
+	"net/http"
+
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Subject возвращает пользователя из контекста запроса, заполненного JWTAuthMiddleware
+// или JWTOrAPIKeyMiddleware. Возвращает nil для запроса без аутентификации.
+func Subject(c *gin.Context) *models.TelegramUser {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return nil
+	}
+
+	tenantID := c.GetString("tenant_id")
+	if tenantID == "" {
+		tenantID = config.DefaultTenantID
+	}
+
+	return &models.TelegramUser{
+		ID:       userID.(int),
+		TenantID: tenantID,
+		Role:     c.GetString("role"),
+		IsAdmin:  c.GetBool("is_admin"),
+	}
+}
+
+// Require middleware для проверки права action, не связанного с конкретным ресурсом.
+// Используется после JWTAuthMiddleware.
+func Require(action Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := Subject(c)
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := Can(user, action, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,138 @@
+package policy
+
+import (
+	"sync"
+	"time"
+
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+)
+
+// rules права ролей: область действия -> роль -> права
+type rules map[string]map[string]map[Action]bool
+
+// allows проверяет, дает ли хотя бы одна из ролей области scope право action
+func (r rules) allows(scope string, roles []string, action Action) bool {
+	for _, role := range roles {
+		if r[scope][role][action] {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleCache закешированные права ролей. Изменение ролей на другом экземпляре бэкенда
+// начинает действовать не позже чем через PermissionCacheTTL.
+var ruleCache = struct {
+	sync.Mutex
+	rules     rules
+	checkedAt time.Time
+}{}
+
+// loadRules возвращает права ролей из кеша или базы данных
+func loadRules() (rules, error) {
+	ruleCache.Lock()
+	defer ruleCache.Unlock()
+
+	if ruleCache.rules != nil && time.Since(ruleCache.checkedAt) < config.GetConfig().PermissionCacheTTL {
+		return ruleCache.rules, nil
+	}
+
+	roles, err := models.GetRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := rules{}
+	for _, role := range roles {
+		if loaded[role.Scope] == nil {
+			loaded[role.Scope] = map[string]map[Action]bool{}
+		}
+		actions := map[Action]bool{}
+		for _, permission := range role.Permissions {
+			actions[Action(permission)] = true
+		}
+		loaded[role.Scope][role.Name] = actions
+	}
+
+	ruleCache.rules = loaded
+	ruleCache.checkedAt = time.Now()
+	return loaded, nil
+}
+
+// Invalidate сбрасывает закешированные права ролей на этом экземпляре бэкенда.
+// Вызывается после изменения ролей, чтобы они начали действовать сразу.
+func Invalidate() {
+	ruleCache.Lock()
+	defer ruleCache.Unlock()
+
+	ruleCache.rules = nil
+}
+
+// globalRoles возвращает глобальные роли пользователя
+func globalRoles(user *models.TelegramUser) []string {
+	roles := []string{models.RoleUser}
+	if user.Role != "" {
+		roles = append(roles, user.Role)
+	}
+	if user.IsAdmin {
+		roles = append(roles, models.RoleAdmin)
+	}
+	return roles
+}
+
+// Can проверяет, есть ли у пользователя право action на ресурс resource.
+// resource - nil для прав, не связанных с ресурсом, *models.Session или *models.SessionTemplate.
+// Право, выданное глобальной ролью, действует на все ресурсы бота пользователя;
+// ресурсы других ботов недоступны никому.
+func Can(user *models.TelegramUser, action Action, resource interface{}) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	// Ресурсы других ботов считаются недоступными
+	switch r := resource.(type) {
+	case *models.Session:
+		if r.TenantID != user.TenantID {
+			return false, nil
+		}
+	case *models.SessionTemplate:
+		if r.TenantID != user.TenantID {
+			return false, nil
+		}
+	}
+
+	rules, err := loadRules()
+	if err != nil {
+		return false, err
+	}
+
+	if rules.allows(models.RoleScopeGlobal, globalRoles(user), action) {
+		return true, nil
+	}
+
+	switch r := resource.(type) {
+	case *models.Session:
+		var roles []string
+		if r.ArchitectID == user.ID {
+			roles = append(roles, models.RoleOwner)
+		}
+
+		role, err := models.GetSessionMemberRole(r.ID, user.ID)
+		if err != nil {
+			return false, err
+		}
+		if role != "" {
+			roles = append(roles, role)
+		}
+
+		return rules.allows(models.RoleScopeSession, roles, action), nil
+
+	case *models.SessionTemplate:
+		if r.OwnerID == user.ID {
+			return rules.allows(models.RoleScopeTemplate, []string{models.RoleOwner}, action), nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit параметры корзины токенов: Burst запросов подряд, после чего запросы
+// разрешаются со скоростью Rate запросов в секунду
+type Limit struct {
+	Rate   float64
+	Burst  int
+	Window time.Duration // период из настроек, используется в заголовке RateLimit-Policy
+}
+
+// Result результат попытки взять токен из корзины
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // через сколько появится следующий токен, если запрос отклонен
+	Reset      time.Duration // через сколько корзина заполнится полностью
+}
+
+// ParseLimit разбирает ограничение в формате "N/период" или "N/период,burst", где период -
+// s, m, h или d (например, "10/m" или "100/h,20"). Пустая строка или "0" отключают ограничение.
+func ParseLimit(value string) (*Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return nil, nil
+	}
+
+	spec, burstStr, hasBurst := strings.Cut(value, ",")
+	countStr, periodStr, found := strings.Cut(spec, "/")
+	if !found {
+		return nil, fmt.Errorf("invalid rate limit %q: expected N/period", value)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("invalid rate limit %q: count must be positive", value)
+	}
+
+	var window time.Duration
+	switch strings.TrimSpace(periodStr) {
+	case "s":
+		window = time.Second
+	case "m":
+		window = time.Minute
+	case "h":
+		window = time.Hour
+	case "d":
+		window = 24 * time.Hour
+	default:
+		return nil, fmt.Errorf("invalid rate limit %q: period must be s, m, h or d", value)
+	}
+
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(strings.TrimSpace(burstStr))
+		if err != nil || burst <= 0 {
+			return nil, fmt.Errorf("invalid rate limit %q: burst must be positive", value)
+		}
+	}
+
+	return &Limit{
+		Rate:   float64(count) / window.Seconds(),
+		Burst:  burst,
+		Window: window,
+	}, nil
+}
+
+// take пополняет корзину за прошедшее время и берет из нее токен, если он есть.
+// Возвращает новое количество токенов и результат.
+func (l Limit) take(tokens float64, elapsed time.Duration) (float64, Result) {
+	tokens = math.Min(float64(l.Burst), tokens+elapsed.Seconds()*l.Rate)
+
+	result := Result{Allowed: tokens >= 1}
+	if result.Allowed {
+		tokens--
+	} else {
+		result.RetryAfter = l.timeFor(1 - tokens)
+	}
+
+	result.Remaining = int(tokens)
+	result.Reset = l.timeFor(float64(l.Burst) - tokens)
+	return tokens, result
+}
+
+// timeFor возвращает время, за которое в корзину поступит указанное количество токенов
+func (l Limit) timeFor(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.Rate * float64(time.Second))
+}
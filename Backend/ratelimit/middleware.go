@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"expvar"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"prophecy/backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc возвращает ключ, по которому считаются запросы
+type KeyFunc func(c *gin.Context) string
+
+// ByIP считает запросы по IP адресу клиента. Заголовки X-Forwarded-For и X-Real-IP учитываются
+// только от доверенных прокси из TRUSTED_PROXIES, иначе клиент мог бы менять ключ в каждом запросе.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser считает запросы по ID пользователя, а для запросов без аутентификации - по IP адресу.
+// Используется после JWTAuthMiddleware.
+func ByUser(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return "user:" + strconv.Itoa(userID.(int))
+	}
+	return ByIP(c)
+}
+
+// metrics счетчики ограничения частоты запросов, доступные через expvar:
+// <правило>.allowed, <правило>.limited и store_errors
+var metrics = expvar.NewMap("rate_limit")
+
+var (
+	storeOnce sync.Once
+	store     Store
+)
+
+// getStore возвращает хранилище корзин из настроек RATE_LIMIT_STORE
+func getStore() Store {
+	storeOnce.Do(func() {
+		if config.GetConfig().RateLimitStore == "postgres" {
+			store = PostgresStore{}
+		} else {
+			store = NewMemoryStore()
+		}
+	})
+	return store
+}
+
+// Middleware ограничивает частоту запросов по правилу rule. Ограничение задается в формате ParseLimit,
+// запросы считаются отдельно для каждого ключа key. Ошибка в формате ограничения останавливает запуск,
+// чтобы опечатка в настройках не отключала защиту незаметно.
+func Middleware(rule, limitSpec string, key KeyFunc) gin.HandlerFunc {
+	limit, err := ParseLimit(limitSpec)
+	if err != nil {
+		log.Fatalf("Invalid rate limit for %s: %v", rule, err)
+	}
+
+	if limit == nil || !config.GetConfig().RateLimitEnabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	policy := strconv.Itoa(int(math.Round(limit.Rate*limit.Window.Seconds()))) +
+		";w=" + strconv.Itoa(int(limit.Window.Seconds())) +
+		";burst=" + strconv.Itoa(limit.Burst)
+
+	return func(c *gin.Context) {
+		result, err := getStore().Take(rule+":"+key(c), *limit)
+		if err != nil {
+			// При недоступности хранилища запросы пропускаются, чтобы не блокировать всех пользователей
+			log.Printf("Rate limit store error for %s: %v", rule, err)
+			metrics.Add("store_errors", 1)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			metrics.Add(rule+".limited", 1)
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			c.Abort()
+			return
+		}
+
+		metrics.Add(rule+".allowed", 1)
+		c.Next()
+	}
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"prophecy/backend/models"
+)
+
+// Store хранилище корзин токенов
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+}
+
+// sweepInterval как часто MemoryStore удаляет заполнившиеся корзины
+const sweepInterval = time.Minute
+
+// bucket корзина токенов в памяти
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time // когда корзина заполнится полностью и станет неотличима от новой
+}
+
+// MemoryStore хранит корзины в памяти экземпляра бэкенда: каждый экземпляр считает запросы отдельно
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore создает хранилище корзин в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+// Take берет токен из корзины key
+func (s *MemoryStore) Take(key string, limit Limit) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Не чаще раза в sweepInterval удаляем заполнившиеся корзины: новая корзина для того же ключа
+	// будет такой же полной, поэтому ограничения не меняются, а хранилище не растет бесконечно.
+	// Обход всех корзин выполняется редко, а не на каждый запрос.
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.fullAt) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	tokens, result := limit.take(b.tokens, now.Sub(b.updatedAt))
+	b.tokens = tokens
+	b.updatedAt = now
+	b.fullAt = now.Add(result.Reset)
+	return result, nil
+}
+
+// PostgresStore хранит корзины в базе данных, поэтому ограничения общие для всех экземпляров бэкенда
+type PostgresStore struct{}
+
+// Take берет токен из корзины key
+func (PostgresStore) Take(key string, limit Limit) (Result, error) {
+	var result Result
+	err := models.UpdateRateLimitBucket(key, float64(limit.Burst), func(tokens float64, elapsed time.Duration) float64 {
+		tokens, result = limit.take(tokens, elapsed)
+		return tokens
+	})
+	return result, err
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"prophecy/backend/auth"
+	"prophecy/backend/handlers"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAdminRoutes регистрирует маршруты для выдачи и снятия прав администратора
+func RegisterAdminRoutes(router gin.IRouter) {
+	// Права администратора (требуется право users.manage_admins)
+	adminGroup := router.Group("/admin/users/:id/admin")
+	adminGroup.Use(auth.JWTAuthMiddleware(), policy.Require(policy.UsersManageAdmins))
+	{
+		adminGroup.POST("", handlers.GrantUserAdmin)
+		adminGroup.DELETE("", handlers.RevokeUserAdmin)
+	}
+}
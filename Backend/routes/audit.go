@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"prophecy/backend/auth"
+	"prophecy/backend/handlers"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAuditRoutes регистрирует маршруты журнала аудита
+func RegisterAuditRoutes(router gin.IRouter) {
+	// Журнал привилегированных действий (требуется право audit.view)
+	router.GET("/admin/audit", auth.JWTAuthMiddleware(), policy.Require(policy.AuditView), handlers.GetAuditLog)
+}
@@ -2,20 +2,47 @@ package routes
 
 import (
 	"prophecy/backend/auth"
+	"prophecy/backend/config"
 	"prophecy/backend/handlers"
+	"prophecy/backend/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RegisterAuthRoutes регистрирует маршруты для аутентификации
 func RegisterAuthRoutes(router gin.IRouter) {
+	// Ограничение частоты входов и обновлений токенов с одного IP адреса
+	authLimit := ratelimit.Middleware("auth", config.GetConfig().RateLimitAuth, ratelimit.ByIP)
+
 	// Маршруты для аутентификации Telegram WebApp
-	router.POST("/auth/telegram", handlers.ValidateTelegramToken)
-	router.GET("/auth/telegram/token", handlers.GetTelegramBotToken)
+	router.POST("/auth/telegram", authLimit, handlers.ValidateTelegramToken)
+	router.POST("/auth/telegram/widget", authLimit, handlers.ValidateTelegramLoginWidget)
+
+	// Боты (арендаторы): оформление и вход через конкретного бота
+	router.GET("/auth/bots", handlers.GetTelegramBots)
+	router.GET("/auth/bots/:bot", handlers.GetTelegramBot)
+	router.POST("/auth/bots/:bot/telegram", authLimit, handlers.ValidateTelegramToken)
+	router.POST("/auth/bots/:bot/telegram/widget", authLimit, handlers.ValidateTelegramLoginWidget)
+
+	// Вход под вымышленными пользователями Telegram в режиме разработки
+	if config.GetConfig().DevAuthEnabled {
+		router.POST("/auth/dev/init-data", authLimit, handlers.CreateDevInitData)
+		router.POST("/auth/bots/:bot/dev/init-data", authLimit, handlers.CreateDevInitData)
+	}
 
 	// Маршруты для работы с JWT
 	router.GET("/auth/verify", auth.JWTAuthMiddleware(), handlers.VerifyJWT)
 
+	// Обновление токенов и управление входами
+	router.POST("/auth/refresh", authLimit, handlers.RefreshToken)
+	router.POST("/auth/logout", auth.JWTAuthMiddleware(), handlers.Logout)
+	router.GET("/auth/logins", auth.JWTAuthMiddleware(), handlers.GetLogins)
+	router.DELETE("/auth/logins", auth.JWTAuthMiddleware(), handlers.RevokeAllLogins)
+	router.DELETE("/auth/logins/:login_id", auth.JWTAuthMiddleware(), handlers.RevokeLogin)
+
+	// Открытые ключи для проверки токенов другими сервисами
+	router.GET("/.well-known/jwks.json", handlers.GetJWKS)
+
 	// Маршрут для проверки статуса администратора
 	router.GET("/auth/admin", auth.JWTAuthMiddleware(), handlers.CheckAdminStatus)
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"expvar"
+
+	"prophecy/backend/auth"
 	"prophecy/backend/handlers"
+	"prophecy/backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,4 +15,7 @@ func RegisterBaseRoutes(router gin.IRouter) {
 	// Базовые маршруты
 	router.GET("/", handlers.Welcome)
 	router.GET("/health", handlers.HealthCheck)
+
+	// Метрики expvar, в том числе счетчики ограничения частоты запросов (требуется право debug.view)
+	router.GET("/debug/vars", auth.JWTAuthMiddleware(), policy.Require(policy.DebugView), gin.WrapH(expvar.Handler()))
 }
@@ -3,12 +3,22 @@ package routes
 import (
 	"prophecy/backend/auth"
 	"prophecy/backend/handlers"
+	"prophecy/backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RegisterRoleRoutes регистрирует маршруты для работы с ролями пользователей
 func RegisterRoleRoutes(router *gin.Engine) {
-	// Маршрут для установки роли пользователю (только для администраторов)
-	router.PUT("/users/:id/role", auth.JWTAuthMiddleware(), auth.AdminAuthMiddleware(), handlers.SetUserRole)
+	// Маршрут для установки роли пользователю (требуется право users.set_role)
+	router.PUT("/users/:id/role", auth.JWTAuthMiddleware(), policy.Require(policy.UsersSetRole), handlers.SetUserRole)
+
+	// Роли и их права (требуется право roles.manage)
+	roleGroup := router.Group("/admin/roles")
+	roleGroup.Use(auth.JWTAuthMiddleware(), policy.Require(policy.RolesManage))
+	{
+		roleGroup.GET("", handlers.GetRoles)
+		roleGroup.PUT("/:role", handlers.SaveRole)
+		roleGroup.DELETE("/:role", handlers.DeleteRole)
+	}
 }
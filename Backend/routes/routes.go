@@ -1,15 +1,26 @@
 package routes
 
 import (
+	"prophecy/backend/config"
+	"prophecy/backend/ratelimit"
+
 	"github.com/gin-gonic/gin"
 )
 
 // RegisterRoutes регистрирует все маршруты приложения
 func RegisterRoutes(router *gin.Engine) {
+	// Общее ограничение частоты запросов с одного IP адреса
+	router.Use(ratelimit.Middleware("global", config.GetConfig().RateLimitGlobal, ratelimit.ByIP))
+
 	// Регистрация маршрутов из разных категорий
 	RegisterBaseRoutes(router)
 	RegisterUserRoutes(router)
 	RegisterAuthRoutes(router)
 	RegisterRoleRoutes(router)
 	RegisterSessionRoutes(router)
+	RegisterTemplateRoutes(router)
+	RegisterServiceAccountRoutes(router)
+	RegisterAuditRoutes(router)
+	RegisterAdminRoutes(router)
+	RegisterSuspensionRoutes(router)
 }
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"prophecy/backend/auth"
+	"prophecy/backend/handlers"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterServiceAccountRoutes регистрирует маршруты для управления сервисными аккаунтами и их API ключами
+func RegisterServiceAccountRoutes(router gin.IRouter) {
+	// Группа маршрутов для пользователей с правом service_accounts.manage (администраторов)
+	accountGroup := router.Group("/admin/service-accounts")
+	accountGroup.Use(auth.JWTAuthMiddleware(), policy.Require(policy.ServiceAccountsManage))
+	{
+		// Создание, список и отключение сервисных аккаунтов
+		accountGroup.POST("", handlers.CreateServiceAccount)
+		accountGroup.GET("", handlers.GetServiceAccounts)
+		accountGroup.DELETE("/:id", handlers.DisableServiceAccount)
+
+		// Выпуск, список и отзыв API ключей
+		accountGroup.POST("/:id/keys", handlers.CreateServiceAccountAPIKey)
+		accountGroup.GET("/:id/keys", handlers.GetServiceAccountAPIKeys)
+		accountGroup.DELETE("/:id/keys/:key_id", handlers.RevokeServiceAccountAPIKey)
+	}
+}
@@ -2,31 +2,48 @@ package routes
 
 import (
 	"prophecy/backend/auth"
+	"prophecy/backend/config"
 	"prophecy/backend/handlers"
+	"prophecy/backend/models"
+	"prophecy/backend/policy"
+	"prophecy/backend/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RegisterSessionRoutes регистрирует маршруты для работы с сессиями
 func RegisterSessionRoutes(router gin.IRouter) {
-	// Группа маршрутов для сессий с JWT аутентификацией
+	// Группа маршрутов для сессий с JWT аутентификацией или API ключом сервисного аккаунта
 	sessionGroup := router.Group("/sessions")
-	sessionGroup.Use(auth.JWTAuthMiddleware())
+	sessionGroup.Use(auth.JWTOrAPIKeyMiddleware(models.ScopeSessionsRead, models.ScopeSessionsWrite))
 	{
-		// Создание новой сессии (только для архитекторов и админов)
-		sessionGroup.POST("", auth.ArchitectOrAdminMiddleware(), handlers.CreateSession)
+		// Ограничение частоты создания сессий и вступления в них для одного пользователя
+		createLimit := ratelimit.Middleware("session_create", config.GetConfig().RateLimitSessionCreate, ratelimit.ByUser)
+		joinLimit := ratelimit.Middleware("join", config.GetConfig().RateLimitJoin, ratelimit.ByUser)
+
+		// Создание новой сессии (требуется право session.create)
+		sessionGroup.POST("", createLimit, policy.Require(policy.SessionCreate), handlers.CreateSession)
 
 		// Получение списка сессий
 		sessionGroup.GET("", handlers.GetSessions)
 
+		// Открытые публичные сессии и вступление в них без приглашения
+		sessionGroup.GET("/public", handlers.GetPublicSessions)
+		sessionGroup.POST("/:id/join", joinLimit, handlers.JoinPublicSession)
+
 		// Получение информации о конкретной сессии
 		sessionGroup.GET("/:id", handlers.GetSession)
 
 		// Обновление информации о сессии
 		sessionGroup.PUT("/:id", handlers.UpdateSession)
 
-		// Удаление сессии
+		// Удаление сессии (мягкое, админ может восстановить сессию)
 		sessionGroup.DELETE("/:id", handlers.DeleteSession)
+		sessionGroup.POST("/:id/restore", policy.Require(policy.SessionRestore), handlers.RestoreSession)
+
+		// Архивирование завершенных сессий
+		sessionGroup.POST("/:id/archive", handlers.ArchiveSession)
+		sessionGroup.POST("/:id/unarchive", handlers.UnarchiveSession)
 
 		// Добавление игрока к сессии
 		sessionGroup.POST("/:id/players", handlers.AddPlayerToSession)
@@ -34,12 +51,50 @@ func RegisterSessionRoutes(router gin.IRouter) {
 		// Удаление игрока из сессии
 		sessionGroup.DELETE("/:id/players", handlers.RemovePlayerFromSession)
 
+		// Исправление команды и клана игрока (требуется право session.moderate)
+		sessionGroup.PUT("/:id/players/:user_id", handlers.UpdateSessionPlayer)
+
+		// Баны игроков в сессии
+		sessionGroup.POST("/:id/bans", handlers.BanSessionPlayer)
+		sessionGroup.GET("/:id/bans", handlers.GetSessionBans)
+		sessionGroup.DELETE("/:id/bans/:user_id", handlers.LiftSessionBan)
+
 		// Получение всех игроков в сессии
 		sessionGroup.GET("/:id/players", handlers.GetSessionPlayers)
 
+		// Сохранение сессии как шаблона и клонирование без игроков
+		sessionGroup.POST("/:id/template", policy.Require(policy.TemplateCreate), handlers.SaveSessionAsTemplate)
+		sessionGroup.POST("/:id/clone", createLimit, policy.Require(policy.SessionCreate), handlers.CloneSession)
+
+		// Участники сессии и их роли
+		sessionGroup.GET("/:id/members", handlers.GetSessionMembers)
+		sessionGroup.PUT("/:id/members/:user_id", handlers.SetSessionMemberRole)
+		sessionGroup.DELETE("/:id/members/:user_id", handlers.RevokeSessionMemberRole)
+
+		// Импорт списка игроков из CSV и игроки, ожидающие первого входа
+		sessionGroup.POST("/:id/roster", handlers.ImportSessionRoster)
+		sessionGroup.GET("/:id/roster", handlers.GetSessionRoster)
+		sessionGroup.DELETE("/:id/roster/:entry_id", handlers.DeleteSessionRosterEntry)
+
+		// Управление приглашениями в сессию
+		sessionGroup.POST("/:id/invites", handlers.CreateSessionInvite)
+		sessionGroup.GET("/:id/invites", handlers.GetSessionInvites)
+		sessionGroup.DELETE("/:id/invites/:invite_id", handlers.RevokeSessionInvite)
+		sessionGroup.GET("/:id/invites/:invite_id/players", handlers.GetSessionInvitePlayers)
+
+		// Смена и снятие PIN-кода сессии
+		sessionGroup.POST("/:id/pin", handlers.RotateSessionPin)
+		sessionGroup.DELETE("/:id/pin", handlers.RemoveSessionPin)
+
+		// Заявки на вступление в сессию с одобрением
+		sessionGroup.GET("/:id/join-requests", handlers.GetSessionJoinRequests)
+		sessionGroup.POST("/:id/join-requests/approve", handlers.ApproveSessionJoinRequests)
+		sessionGroup.POST("/:id/join-requests/reject", handlers.RejectSessionJoinRequests)
+
 		// Присоединение к сессии по реферальной ссылке
-		sessionGroup.POST("/join/:referral_link", handlers.JoinSessionByReferral)
-		sessionGroup.GET("/join/:referral_link", handlers.JoinSessionByReferral)
+		sessionGroup.POST("/join/:referral_link", joinLimit, handlers.JoinSessionByReferral)
+		// GET тоже добавляет игрока в сессию, поэтому для API ключа требуется область записи
+		sessionGroup.GET("/join/:referral_link", joinLimit, auth.RequireAPIKeyScope(models.ScopeSessionsWrite), handlers.JoinSessionByReferral)
 	}
 
 	// Группа маршрутов для получения сессий игрока
@@ -48,5 +103,8 @@ func RegisterSessionRoutes(router gin.IRouter) {
 	{
 		// Получение всех сессий, в которых участвует игрок
 		playerGroup.GET("/sessions", handlers.GetPlayerSessions)
+
+		// Получение заявок игрока на вступление в сессии
+		playerGroup.GET("/join-requests", handlers.GetPlayerJoinRequests)
 	}
 }
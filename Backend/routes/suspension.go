@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"prophecy/backend/auth"
+	"prophecy/backend/handlers"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSuspensionRoutes регистрирует маршруты для блокировки пользователей
+func RegisterSuspensionRoutes(router gin.IRouter) {
+	// Группа маршрутов для пользователей с правом users.suspend (администраторов)
+	suspensionGroup := router.Group("/admin")
+	suspensionGroup.Use(auth.JWTAuthMiddleware(), policy.Require(policy.UsersSuspend))
+	{
+		// Действующие блокировки бота
+		suspensionGroup.GET("/suspensions", handlers.GetSuspensions)
+
+		// Блокировка пользователя, ее снятие и история блокировок
+		suspensionGroup.POST("/users/:id/suspension", handlers.SuspendUser)
+		suspensionGroup.DELETE("/users/:id/suspension", handlers.LiftUserSuspension)
+		suspensionGroup.GET("/users/:id/suspensions", handlers.GetUserSuspensions)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"prophecy/backend/auth"
+	"prophecy/backend/handlers"
+	"prophecy/backend/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterTemplateRoutes регистрирует маршруты для работы с шаблонами сессий
+func RegisterTemplateRoutes(router gin.IRouter) {
+	// Шаблоны доступны пользователям с правом template.create (архитекторам и админам)
+	templateGroup := router.Group("/templates")
+	templateGroup.Use(auth.JWTAuthMiddleware(), policy.Require(policy.TemplateCreate))
+	{
+		templateGroup.POST("", handlers.CreateSessionTemplate)
+		templateGroup.GET("", handlers.GetSessionTemplates)
+		templateGroup.GET("/:id", handlers.GetSessionTemplate)
+		templateGroup.PUT("/:id", handlers.UpdateSessionTemplate)
+		templateGroup.DELETE("/:id", handlers.DeleteSessionTemplate)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"prophecy/backend/auth"
 	"prophecy/backend/handlers"
+	"prophecy/backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,9 +14,9 @@ func RegisterUserRoutes(router gin.IRouter) {
 	router.GET("/users/:id", handlers.GetUser)
 	router.POST("/users", handlers.CreateUser)
 
-	// Маршрут для получения списка всех пользователей (только для администраторов)
-	router.GET("/users", auth.JWTAuthMiddleware(), auth.AdminAuthMiddleware(), handlers.GetAllUsers)
+	// Маршрут для получения списка всех пользователей (требуется право users.view)
+	router.GET("/users", auth.JWTAuthMiddleware(), policy.Require(policy.UsersView), handlers.GetAllUsers)
 
-	// Маршрут для получения статистики пользователей (только для администраторов)
-	router.GET("/users/stats", auth.JWTAuthMiddleware(), auth.AdminAuthMiddleware(), handlers.GetUserStats)
+	// Маршрут для получения статистики пользователей (требуется право users.view)
+	router.GET("/users/stats", auth.JWTAuthMiddleware(), policy.Require(policy.UsersView), handlers.GetUserStats)
 }
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"prophecy/backend/config"
+	"prophecy/backend/models"
+	"prophecy/backend/notify"
+)
+
+// Start запускает фоновый планировщик сессий.
+// Планировщик открывает лобби, запускает и завершает игры по расписанию,
+// напоминает игрокам о скором начале сессии, окончательно удаляет сессии,
+// срок хранения которых после удаления истек, и очищает устаревшие входы.
+func Start(ctx context.Context) {
+	cfg := config.GetConfig()
+
+	go func() {
+		ticker := time.NewTicker(cfg.SchedulerInterval)
+		defer ticker.Stop()
+
+		// Первый проход сразу после запуска, чтобы наверстать пропущенные переходы
+		runOnce(cfg)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runOnce(cfg)
+			}
+		}
+	}()
+}
+
+// runOnce выполняет один проход планировщика
+func runOnce(cfg *config.Config) {
+	if ids, err := models.OpenDueLobbies(cfg.LobbyLeadTime); err != nil {
+		log.Printf("Scheduler: failed to open lobbies: %v", err)
+	} else if len(ids) > 0 {
+		fmt.Printf("Scheduler: opened lobbies for sessions %v\n", ids)
+	}
+
+	if ids, err := models.StartDueSessions(); err != nil {
+		log.Printf("Scheduler: failed to start sessions: %v", err)
+	} else if len(ids) > 0 {
+		fmt.Printf("Scheduler: started sessions %v\n", ids)
+	}
+
+	if ids, err := models.FinishDueSessions(); err != nil {
+		log.Printf("Scheduler: failed to finish sessions: %v", err)
+	} else if len(ids) > 0 {
+		fmt.Printf("Scheduler: finished sessions %v\n", ids)
+	}
+
+	sendStartNotifications(cfg)
+
+	if ids, err := models.PurgeDeletedSessions(time.Now().Add(-cfg.SessionRetention)); err != nil {
+		log.Printf("Scheduler: failed to purge deleted sessions: %v", err)
+	} else if len(ids) > 0 {
+		fmt.Printf("Scheduler: purged deleted sessions %v\n", ids)
+	}
+
+	// Отозванные и истекшие входы хранятся сутки, чтобы повторное использование их токенов отклонялось явно
+	if count, err := models.PurgeStaleLogins(time.Now().Add(-24 * time.Hour)); err != nil {
+		log.Printf("Scheduler: failed to purge stale logins: %v", err)
+	} else if count > 0 {
+		fmt.Printf("Scheduler: purged %d stale logins\n", count)
+	}
+
+	if count, err := models.PurgeExpiredAuthReplayKeys(); err != nil {
+		log.Printf("Scheduler: failed to purge auth replay keys: %v", err)
+	} else if count > 0 {
+		fmt.Printf("Scheduler: purged %d auth replay keys\n", count)
+	}
+
+	if count, err := models.PurgeStaleRateLimitBuckets(time.Now().Add(-24 * time.Hour)); err != nil {
+		log.Printf("Scheduler: failed to purge rate limit buckets: %v", err)
+	} else if count > 0 {
+		fmt.Printf("Scheduler: purged %d rate limit buckets\n", count)
+	}
+}
+
+// sendStartNotifications напоминает игрокам о скором начале сессий
+func sendStartNotifications(cfg *config.Config) {
+	sessions, err := models.ClaimStartNotifications(cfg.StartNotifyLeadTime)
+	if err != nil {
+		log.Printf("Scheduler: failed to claim start notifications: %v", err)
+		return
+	}
+
+	for _, session := range sessions {
+		players, err := models.GetSessionPlayers(session.ID)
+		if err != nil {
+			log.Printf("Scheduler: failed to get players of session %d: %v", session.ID, err)
+			continue
+		}
+
+		minutes := int(time.Until(*session.StartsAt).Round(time.Minute).Minutes())
+		text := fmt.Sprintf("Сессия «%s» начнется через %d мин.", session.Name, minutes)
+
+		for _, player := range players {
+			if err := notify.SendTelegramMessage(session.TenantID, player.TelegramID, text); err != nil {
+				log.Printf("Scheduler: failed to notify player %d about session %d: %v", player.ID, session.ID, err)
+			}
+		}
+	}
+}
@@ -1,5 +1,5 @@
 import { prepareAuthPayload, sendAuthToServer as sendAuthToServerUtil } from '../index.js';
-import { saveJWTToken, clearJWTToken } from './jwt.js';
+import { saveAuthTokens, clearJWTToken } from './jwt.js';
 import { retryWithBackoff } from '../utils/retry.js';
 
 /**
@@ -24,7 +24,7 @@ export class AuthService {
         const response = await sendAuthToServerUtil(payload, endpoint);
         
         if (response?.token) {
-          saveJWTToken(response.token);
+          saveAuthTokens(response);
           return response;
         } else {
           throw new Error('Сервер не вернул токен');
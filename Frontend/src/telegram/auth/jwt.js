@@ -33,20 +33,50 @@ export function getJWTToken() {
 }
 
 /**
- * Удаляет JWT токен из localStorage
+ * Сохраняет refresh токен в localStorage
+ * @param {string} refreshToken - Refresh токен
+ */
+export function saveRefreshToken(refreshToken) {
+  if (refreshToken) {
+    localStorage.setItem("refresh_token", refreshToken);
+    console.log("✅ Refresh токен сохранен в localStorage");
+  }
+}
+
+/**
+ * Получает refresh токен из localStorage
+ * @returns {string|null} Refresh токен или null
+ */
+export function getRefreshToken() {
+  return localStorage.getItem("refresh_token");
+}
+
+/**
+ * Сохраняет пару токенов из ответа сервера (вход или обновление токена)
+ * @param {Object} data - Ответ сервера с полями token и refresh_token
+ */
+export function saveAuthTokens(data) {
+  saveJWTToken(data?.token);
+  saveRefreshToken(data?.refresh_token);
+}
+
+/**
+ * Удаляет JWT токен и refresh токен из localStorage
  */
 export function clearJWTToken() {
   console.log("🔍 Удаление JWT токена из localStorage");
   localStorage.removeItem("jwt_token");
+  localStorage.removeItem("refresh_token");
   console.log("🗑️ JWT токен удален из localStorage");
 }
 
 /**
  * Проверяет валидность JWT токена
  * @param {string} token - JWT токен
+ * @param {number} leewaySeconds - Запас в секундах: токен, истекающий раньше, считается недействительным
  * @returns {boolean} true если токен валиден, false если нет
  */
-export function isTokenValid(token) {
+export function isTokenValid(token, leewaySeconds = 0) {
   console.log("🔍 Проверка валидности токена");
   if (!token || typeof token !== "string") {
     console.log("⚠️ Токен отсутствует или не является строкой");
@@ -86,7 +116,7 @@ export function isTokenValid(token) {
     }
 
     const currentTime = Math.floor(Date.now() / 1000);
-    const isValid = payload.exp > currentTime;
+    const isValid = payload.exp > currentTime + leewaySeconds;
     console.log("🔍 Сравнение времени окончания действия токена:", {
       exp: payload.exp,
       currentTime: currentTime,
@@ -114,6 +144,76 @@ export function hasValidToken() {
   return isValid;
 }
 
+// Текущий запрос обновления токена. Refresh токен одноразовый, поэтому параллельные
+// запросы должны дождаться одного обновления, а не отправлять тот же токен повторно
+let refreshPromise = null;
+
+/**
+ * Обменивает refresh токен на новую пару токенов
+ * @returns {Promise<boolean>} true если токены обновлены, false если войти заново нужно через Telegram
+ */
+export function refreshAccessToken() {
+  if (refreshPromise) {
+    return refreshPromise;
+  }
+
+  const refreshToken = getRefreshToken();
+  if (!refreshToken) {
+    return Promise.resolve(false);
+  }
+
+  refreshPromise = (async () => {
+    try {
+      console.log("🔄 Обновление токена доступа по refresh токену");
+      const response = await fetch("/api/auth/refresh", {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+        },
+        body: JSON.stringify({ refresh_token: refreshToken }),
+      });
+
+      if (response.status === 401 || response.status === 403) {
+        // Вход отозван, истек или пользователь заблокирован
+        console.log("⚠️ Refresh токен недействителен, требуется повторный вход");
+        clearJWTToken();
+        return false;
+      }
+
+      if (!response.ok) {
+        throw new Error(`Server responded with ${response.status}`);
+      }
+
+      const data = await response.json();
+      saveAuthTokens(data);
+      console.log("✅ Токен доступа обновлен");
+      return true;
+    } catch (error) {
+      // Сетевая ошибка: refresh токен мог остаться действительным, поэтому он не удаляется
+      console.warn("❌ Не удалось обновить токен доступа:", error.message);
+      return false;
+    } finally {
+      refreshPromise = null;
+    }
+  })();
+
+  return refreshPromise;
+}
+
+/**
+ * Возвращает действующий токен доступа, обновляя его заранее, если он истекает в ближайшие 30 секунд
+ * @returns {Promise<boolean>} true если есть действующий токен доступа
+ */
+export async function ensureValidToken() {
+  if (isTokenValid(getJWTToken(), 30)) {
+    return true;
+  }
+  if (await refreshAccessToken()) {
+    return true;
+  }
+  return hasValidToken();
+}
+
 /**
  * Добавляет JWT токен к заголовкам запроса
  * @param {Object} headers - Объект заголовков
@@ -155,21 +255,38 @@ export async function authenticatedFetch(url, options = {}, maxRetries = 3) {
 
   for (let attempt = 0; attempt <= maxRetries; attempt++) {
     try {
+      await ensureValidToken();
+
       const headers = addAuthHeader(options.headers || {});
       console.log("🔍 Заголовки запроса:", headers);
-      const response = await fetch(url, {
+      let response = await fetch(url, {
         ...options,
         headers,
       });
       console.log("🔍 Ответ от сервера:", response.status);
 
+      // Токен доступа мог быть отозван или истечь раньше срока: обновляем его один раз и повторяем запрос
+      if (response.status === 401 && (await refreshAccessToken())) {
+        response = await fetch(url, {
+          ...options,
+          headers: addAuthHeader(options.headers || {}),
+        });
+        console.log("🔍 Ответ от сервера после обновления токена:", response.status);
+      }
+
       if (response.status === 401) {
-        // Токен недействителен, очищаем его
+        // Токен недействителен и не обновляется, очищаем его
         console.log("⚠️ Токен авторизации недействителен, очистка токена");
         clearJWTToken();
         throw new Error("Токен авторизации недействителен");
       }
 
+      // Сервер перевыпускает токен, если права пользователя изменились
+      const refreshedToken = response.headers.get("X-Refreshed-Token");
+      if (refreshedToken) {
+        saveJWTToken(refreshedToken);
+      }
+
       return response;
     } catch (error) {
       lastError = error;
@@ -1,5 +1,5 @@
 import { ref } from 'vue'
-import { authenticatedFetch, hasValidToken, getJWTToken } from '../auth/jwt.js'
+import { authenticatedFetch, ensureValidToken, getJWTToken } from '../auth/jwt.js'
 import { useTelegramWebApp } from './useTelegramWebApp.js'
 
 /**
@@ -25,8 +25,8 @@ export function useApi() {
     console.log('Original url:', url, 'Transformed to apiUrl:', apiUrl);
 
     
-    // Проверяем наличие токена
-    if (autoAuth && !hasValidToken()) {
+    // Проверяем наличие токена, при необходимости обновляя его по refresh токену
+    if (autoAuth && !(await ensureValidToken())) {
       // Вместо автоматической аутентификации, просто бросаем ошибку
       // Это предотвращает циклическую зависимость между useApi и useTelegramWebApp
       throw new Error('Нет валидного токена аутентификации. Пожалуйста, выполните аутентификацию отдельно.')
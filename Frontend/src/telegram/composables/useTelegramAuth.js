@@ -1,7 +1,7 @@
 import { ref } from 'vue';
 import { useApi } from './useApi.js';
 import { retryWithBackoff } from '../utils/retry.js';
-import { saveJWTToken, clearJWTToken } from '../auth/jwt.js';
+import { saveAuthTokens, clearJWTToken } from '../auth/jwt.js';
 import { getUserInfoFromToken } from '../auth/user.js';
 import { prepareAuthPayload } from '../auth/server.js';
 
@@ -96,9 +96,9 @@ export function useTelegramAuth(telegramUser, getTelegramAuthData, hasValidToken
           
           const data = await response.json();
           
-          // Если сервер вернул токен, сохраняем его
+          // Если сервер вернул токен, сохраняем его вместе с refresh токеном
           if (data.token) {
-            saveJWTToken(data.token);
+            saveAuthTokens(data);
             console.log("🔐 JWT token received from server");
             return data;
           } else {
@@ -19,6 +19,8 @@ export {
   getJWTToken,
   clearJWTToken,
   hasValidToken,
+  refreshAccessToken,
+  ensureValidToken,
   authenticatedFetch,
 } from "./auth/jwt.js";
 export { getUserInfoFromToken } from "./auth/user.js";